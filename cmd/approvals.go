@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	skill "yafai-skill/proto"
+
+	"github.com/spf13/cobra"
+)
+
+// approvalsCmd manages action calls held for human approval
+var approvalsCmd = &cobra.Command{
+	Use:   "approvals",
+	Short: "List, approve or reject action calls waiting for approval",
+}
+
+var approvalsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List pending approvals",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		action, _ := cmd.Flags().GetString("action")
+
+		client, conn, err := dialSkill()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+		defer cancel()
+		res, err := client.ListPendingApprovals(ctx, &skill.ListPendingApprovalsRequest{Action: action})
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tACTION\tMETHOD\tURL\tEXPIRES")
+		for _, a := range res.Approvals {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", a.Id, a.Action, a.Method, a.Url, a.ExpiresAt.AsTime().Local().Format(time.RFC3339))
+		}
+		return w.Flush()
+	},
+}
+
+var approvalsApproveCmd = &cobra.Command{
+	Use:   "approve <id>",
+	Short: "Approve and execute a pending action call",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, conn, err := dialSkill()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		res, err := client.ApproveAction(cmd.Context(), &skill.ApproveActionRequest{Id: args[0]})
+		if err != nil {
+			return err
+		}
+		fmt.Println(res.Response)
+		return nil
+	},
+}
+
+var approvalsRejectCmd = &cobra.Command{
+	Use:   "reject <id>",
+	Short: "Reject and discard a pending action call",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reason, _ := cmd.Flags().GetString("reason")
+
+		client, conn, err := dialSkill()
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+		defer cancel()
+		res, err := client.RejectAction(ctx, &skill.RejectActionRequest{Id: args[0], Reason: reason})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Rejected %s (%s %s %s)\n", res.Approval.Id, res.Approval.Action, res.Approval.Method, res.Approval.Url)
+		return nil
+	},
+}
+
+func init() {
+	approvalsListCmd.Flags().String("action", "", "Only list approvals for this action")
+	approvalsRejectCmd.Flags().String("reason", "", "Reason recorded with the rejection")
+
+	approvalsCmd.AddCommand(approvalsListCmd, approvalsApproveCmd, approvalsRejectCmd)
+	rootCmd.AddCommand(approvalsCmd)
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	handler "yafai-skill/handler"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultAuditFile is used by --audit-log without a value and by the audit command.
+const defaultAuditFile = "~/.yafai/audit.jsonl"
+
+// expandHome replaces a leading ~/ with the user's home directory.
+func expandHome(path string) (string, error) {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, rest), nil
+}
+
+// parseTimeFlag accepts an RFC 3339 time or a duration meaning that long ago.
+func parseTimeFlag(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected RFC 3339 or a duration such as 24h", value)
+	}
+	return t, nil
+}
+
+func openAuditFile(cmd *cobra.Command) (*os.File, error) {
+	path, _ := cmd.Flags().GetString("file")
+	path, err := expandHome(path)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// auditCmd queries the audit log written with --audit-log
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Query the audit log of executed actions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sinceFlag, _ := cmd.Flags().GetString("since")
+		untilFlag, _ := cmd.Flags().GetString("until")
+		action, _ := cmd.Flags().GetString("action")
+		outcome, _ := cmd.Flags().GetString("outcome")
+		caller, _ := cmd.Flags().GetString("caller")
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		since, err := parseTimeFlag(sinceFlag)
+		if err != nil {
+			return err
+		}
+		until, err := parseTimeFlag(untilFlag)
+		if err != nil {
+			return err
+		}
+
+		f, err := openAuditFile(cmd)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		if !asJSON {
+			fmt.Fprintln(w, "TIME\tCALLER\tACTION\tMETHOD\tSTATUS\tOUTCOME\tDURATION\tREQUEST ID")
+		}
+		err = handler.ScanAudit(f, func(line []byte, e *handler.AuditEntry) error {
+			switch {
+			case !since.IsZero() && e.Time.Before(since),
+				!until.IsZero() && e.Time.After(until),
+				action != "" && e.Action != action,
+				outcome != "" && e.Outcome != outcome,
+				caller != "" && e.Caller != caller:
+				return nil
+			}
+			if asJSON {
+				fmt.Fprintln(w, string(line))
+				return nil
+			}
+			status := "-"
+			if e.Status != 0 {
+				status = fmt.Sprint(e.Status)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%.0fms\t%s\n",
+				e.Time.Local().Format(time.RFC3339), e.Caller, e.Action, e.Method, status, e.Outcome, e.DurationMS, e.RequestID)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return w.Flush()
+	},
+}
+
+var auditVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the hash chain of an audit log written with --audit-chain",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := openAuditFile(cmd)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		n, err := handler.VerifyAudit(f)
+		if err != nil {
+			return fmt.Errorf("audit log is not intact: %w", err)
+		}
+		fmt.Printf("%d entries verified\n", n)
+		return nil
+	},
+}
+
+func init() {
+	auditCmd.PersistentFlags().String("file", defaultAuditFile, "Audit log to read")
+	auditCmd.Flags().String("since", "", "Only entries at or after this time (RFC 3339 or a duration such as 24h)")
+	auditCmd.Flags().String("until", "", "Only entries at or before this time (RFC 3339 or a duration)")
+	auditCmd.Flags().String("action", "", "Only entries for this action")
+	auditCmd.Flags().String("outcome", "", "Only entries with this outcome: success, error, pending_approval, rejected, job_started or cancelled")
+	auditCmd.Flags().String("caller", "", "Only entries from this caller, e.g. token:orchestrator")
+	auditCmd.Flags().Bool("json", false, "Print matching entries as JSON Lines")
+
+	auditCmd.AddCommand(auditVerifyCmd)
+	rootCmd.AddCommand(auditCmd)
+}
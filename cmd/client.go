@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	skill "yafai-skill/proto"
+
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Environment variables configuring how CLI subcommands reach a secured engine.
+const (
+	clientTokenEnv = "YAFAI_SKILL_TOKEN"  // Bearer token sent with every call
+	clientCAEnv    = "YAFAI_SKILL_TLS_CA" // CA bundle verifying the engine's certificate
+	clientCertEnv  = "YAFAI_SKILL_TLS_CERT"
+	clientKeyEnv   = "YAFAI_SKILL_TLS_KEY"
+)
+
+// socketPath is the unix socket the skill engine listens on under the yafai root.
+func socketPath(yafaiRoot string) string {
+	return fmt.Sprintf("%s/plugins/skill.sock", yafaiRoot)
+}
+
+// dialSkill connects to a running skill engine using the configured transport.
+func dialSkill() (skill.SkillServiceClient, *grpc.ClientConn, error) {
+	var target string
+	if os.Getenv("SKILL_TRANSPORT") == "tcp" {
+		target, _ = rootCmd.PersistentFlags().GetString("listen")
+	} else {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get user home directory: %w", err)
+		}
+		target = "unix://" + socketPath(fmt.Sprintf("%s/.yafai", homeDir))
+	}
+
+	creds, err := clientCredentials()
+	if err != nil {
+		return nil, nil, err
+	}
+	dialOpts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if token := os.Getenv(clientTokenEnv); token != "" {
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(bearerCredentials{token: token, secure: os.Getenv(clientCAEnv) != ""}))
+	}
+
+	conn, err := grpc.NewClient(target, dialOpts...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to skill engine at %s: %w", target, err)
+	}
+	return skill.NewSkillServiceClient(conn), conn, nil
+}
+
+func clientCredentials() (credentials.TransportCredentials, error) {
+	caFile := os.Getenv(clientCAEnv)
+	if caFile == "" {
+		return insecure.NewCredentials(), nil
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", clientCAEnv, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("%s holds no PEM certificates", caFile)
+	}
+	cfg := &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	if certFile := os.Getenv(clientCertEnv); certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, os.Getenv(clientKeyEnv))
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(cfg), nil
+}
+
+// bearerCredentials attaches a static bearer token to every call.
+type bearerCredentials struct {
+	token  string
+	secure bool
+}
+
+func (b bearerCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + b.token}, nil
+}
+
+func (b bearerCredentials) RequireTransportSecurity() bool {
+	return b.secure
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	handler "yafai-skill/handler"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// importedAction is an action as written by graphql import, leaving out
+// the settings it does not generate.
+type importedAction struct {
+	Desc             string                   `yaml:"desc"`
+	Type             string                   `yaml:"type"`
+	BaseURL          string                   `yaml:"base_url"`
+	Params           []*importedParam         `yaml:"params,omitempty"`
+	GraphQL          *handler.GraphQLSpec     `yaml:"graphql"`
+	ResponseTemplate handler.ResponseTemplate `yaml:"response_template"`
+}
+
+type importedParam struct {
+	Name       string           `yaml:"name,omitempty"`
+	Type       string           `yaml:"type"`
+	In         string           `yaml:"in,omitempty"`
+	Desc       string           `yaml:"desc,omitempty"`
+	Required   bool             `yaml:"required,omitempty"`
+	Enum       []string         `yaml:"enum,omitempty"`
+	Properties []*importedParam `yaml:"properties,omitempty"`
+	Items      []*importedParam `yaml:"items,omitempty"`
+}
+
+func importedParams(params []*handler.Param) []*importedParam {
+	var out []*importedParam
+	for _, p := range params {
+		out = append(out, &importedParam{
+			Name:       p.Name,
+			Type:       p.Type,
+			In:         p.In,
+			Desc:       p.Desc,
+			Required:   p.Required,
+			Enum:       p.Enum,
+			Properties: importedParams(p.Properties),
+			Items:      importedParams(p.Items),
+		})
+	}
+	return out
+}
+
+// graphqlCmd groups GraphQL tooling
+var graphqlCmd = &cobra.Command{
+	Use:   "graphql",
+	Short: "Generate actions for GraphQL APIs",
+}
+
+var graphqlImportCmd = &cobra.Command{
+	Use:   "import <schema>",
+	Short: "Generate graphql actions from an introspection result or SDL file",
+	Long: `Generate a graphql action for each root query and mutation field of a schema.
+
+The schema is a local file holding either the JSON result of an introspection
+query or the schema in SDL. The generated actions section is printed as YAML
+to paste into a manifest; trim the selected fields and the response templates
+to what the agent needs.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		endpoint, _ := cmd.Flags().GetString("endpoint")
+		fields, _ := cmd.Flags().GetStringSlice("field")
+		mutations, _ := cmd.Flags().GetBool("mutations")
+		depth, _ := cmd.Flags().GetInt("depth")
+		output, _ := cmd.Flags().GetString("output")
+
+		schema, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		actions, err := handler.ImportGraphQL(schema, handler.GraphQLImport{
+			Endpoint:  endpoint,
+			Fields:    fields,
+			Mutations: mutations,
+			Depth:     depth,
+		})
+		if err != nil {
+			return err
+		}
+
+		out := make(map[string]*importedAction, len(actions))
+		for name, a := range actions {
+			out[name] = &importedAction{
+				Desc:             a.Desc,
+				Type:             a.Type,
+				BaseURL:          a.BaseURL,
+				Params:           importedParams(a.Params),
+				GraphQL:          a.GraphQL,
+				ResponseTemplate: a.ResponseTemplate,
+			}
+		}
+		var data bytes.Buffer
+		enc := yaml.NewEncoder(&data)
+		enc.SetIndent(2)
+		if err := enc.Encode(map[string]any{"actions": out}); err != nil {
+			return err
+		}
+		if output == "" {
+			_, err = os.Stdout.Write(data.Bytes())
+			return err
+		}
+		if err := os.WriteFile(output, data.Bytes(), 0644); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d actions to %s\n", len(out), output)
+		return nil
+	},
+}
+
+func init() {
+	graphqlImportCmd.Flags().String("endpoint", "", "GraphQL endpoint the actions call, e.g. https://api.github.com/graphql")
+	graphqlImportCmd.Flags().StringSlice("field", nil, "Only import these root fields (repeatable)")
+	graphqlImportCmd.Flags().Bool("mutations", true, "Import mutation fields as well as query fields")
+	graphqlImportCmd.Flags().Int("depth", 2, "Levels of nested objects the generated queries select")
+	graphqlImportCmd.Flags().StringP("output", "o", "", "Write the actions to this file instead of stdout")
+	graphqlImportCmd.MarkFlagRequired("endpoint")
+
+	graphqlCmd.AddCommand(graphqlImportCmd)
+	rootCmd.AddCommand(graphqlCmd)
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	handler "yafai-skill/handler"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Defaults for rotating --log-file output.
+const (
+	DefaultLogMaxSize    = 100 // megabytes
+	DefaultLogMaxBackups = 5
+)
+
+// LogOptions configures log output.
+type LogOptions struct {
+	Level      string // debug, info, warn or error
+	Format     string // text or json
+	File       string // Log file, empty logs to stderr
+	MaxSize    int    // Megabytes before the file is rotated
+	MaxBackups int    // Rotated files kept
+}
+
+// logOptions reads the level and format flags shared by all commands.
+func logOptions(cmd *cobra.Command) LogOptions {
+	var opts LogOptions
+	opts.Level, _ = cmd.Flags().GetString("log-level")
+	opts.Format, _ = cmd.Flags().GetString("log-format")
+	return opts
+}
+
+// newLogHandler builds the base slog handler writing to w.
+func newLogHandler(w io.Writer, opts LogOptions) (slog.Handler, error) {
+	var level slog.Level
+	if opts.Level != "" {
+		if err := level.UnmarshalText([]byte(opts.Level)); err != nil {
+			return nil, fmt.Errorf("invalid log level %q: %w", opts.Level, err)
+		}
+	}
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	switch opts.Format {
+	case "", "text":
+		return slog.NewTextHandler(w, handlerOpts), nil
+	case "json":
+		return slog.NewJSONHandler(w, handlerOpts), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q, expected text or json", opts.Format)
+	}
+}
+
+// logOutput returns stderr or the rotating log file.
+func logOutput(opts LogOptions) io.WriteCloser {
+	if opts.File == "" {
+		return nopCloser{os.Stderr}
+	}
+	return &lumberjack.Logger{
+		Filename:   opts.File,
+		MaxSize:    opts.MaxSize,
+		MaxBackups: opts.MaxBackups,
+	}
+}
+
+// setLogger installs h as the default logger, adding request IDs and callers
+// from the context to each line.
+func setLogger(h slog.Handler) {
+	slog.SetDefault(slog.New(handler.ContextHandler{Handler: h}))
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
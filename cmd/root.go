@@ -4,43 +4,55 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"io/fs"
 	"log/slog"
-	"net"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 	handler "yafai-skill/handler"
 	skill "yafai-skill/proto"
+	"yafai-skill/redact"
+	"yafai-skill/secrets"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cobra"
 	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
 func ParseAPISpec(path string) (res *handler.APISpec, err error) {
 	yamlFile, err := os.ReadFile(path)
 	if err != nil {
-		slog.Error("Error reading YAML file:", err)
 		return nil, fmt.Errorf("error reading YAML file: %w", err)
 	}
-	slog.Info("Successfully read YAML file: %s", path)
 
 	var apiSpec handler.APISpec
 	err = yaml.Unmarshal(yamlFile, &apiSpec)
 	if err != nil {
-		slog.Error("Error unmarshalling YAML:", err)
 		return nil, fmt.Errorf("error unmarshalling YAML: %w", err)
 	}
-	slog.Info("Successfully unmarshalled YAML data.")
+	slog.Info("Loaded manifest", "path", path, "actions", len(apiSpec.Actions))
 	return &apiSpec, nil
 }
 
-func StartRegisterSkill(path string, key string) error {
+func StartRegisterSkill(path string, key string, opts ServeOptions) error {
+	logOut := logOutput(opts.Log)
+	defer logOut.Close()
+	logHandler, err := newLogHandler(logOut, opts.Log)
+	if err != nil {
+		return err
+	}
+	setLogger(logHandler)
+
 	// Get user home directory
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -48,62 +60,78 @@ func StartRegisterSkill(path string, key string) error {
 	}
 
 	yafaiRoot := fmt.Sprintf("%s/.yafai", homeDir)
-	envFile := fmt.Sprintf("%s/.env", yafaiRoot)
-
-	// Read existing .env file
-	envMap, err := godotenv.Read(envFile)
-	if err != nil {
-		log.Printf("Error reading %s: %v. Creating new file.", envFile, err)
-		envMap = make(map[string]string)
+	if err := os.MkdirAll(yafaiRoot, 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", yafaiRoot, err)
 	}
+	store := secrets.OpenStore(yafaiRoot)
 
-	// Update environment variables
-	envMap["SKILL_KEY"] = key
-	if err := godotenv.Write(envMap, envFile); err != nil {
-		log.Fatalf("failed to write .env file: %v", err)
+	// Earlier versions kept the key in plaintext in .env, move it into the store
+	envFile := fmt.Sprintf("%s/.env", yafaiRoot)
+	if err := migrateEnvKey(envFile, store); err != nil {
+		slog.Warn("Could not migrate skill key out of .env", "error", err)
 	}
 
-	fmt.Printf("Successfully updated %s\n", envFile)
-
-	// Load updated .env
-	if err := godotenv.Load(envFile); err != nil {
-		slog.Error(err.Error())
+	// Load .env for variables read by env secret providers
+	if err := godotenv.Load(envFile); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		slog.Error("Could not load .env", "path", envFile, "error", err)
 	}
 
-	os.Setenv("SKILL_KEY", key)
-
 	// Parse manifest
 	manifest, err := ParseAPISpec(path)
 	if err != nil {
-		slog.Error(err.Error())
+		return err
+	}
+
+	resolver, err := secrets.NewResolver(manifest.Secrets, store)
+	if err != nil {
+		return err
+	}
+	if key != "" {
+		// Kept in memory only, never written to disk or exported
+		resolver.Set(secrets.SkillKey, key)
+	}
+
+	// Route all log output, including the log package, through the redactor
+	redactor, err := redact.New(manifest.Redact, manifest.SensitiveFields()...)
+	if err != nil {
+		return err
+	}
+	redactor.Secrets = resolver.Values
+	setLogger(redact.NewHandler(logHandler, redactor))
+
+	var audit *handler.AuditLog
+	if opts.AuditLog != "" {
+		auditPath, err := expandHome(opts.AuditLog)
+		if err != nil {
+			return err
+		}
+		audit, err = handler.OpenAuditLog(auditPath, opts.AuditChain)
+		if err != nil {
+			return err
+		}
+		defer audit.Close()
+		audit.Redactor = redactor
 	}
 
 	// Ensure plugins directory exists
 	pluginDir := fmt.Sprintf("%s/plugins", yafaiRoot)
 	if err := os.MkdirAll(pluginDir, 0755); err != nil {
-		log.Fatalf("failed to create plugins directory: %v", err)
+		return fmt.Errorf("failed to create plugins directory: %w", err)
 	}
 
 	// Manage socket
-	sockPath := fmt.Sprintf("%s/skill.sock", pluginDir)
+	sockPath := socketPath(yafaiRoot)
 
 	// Clean old socket file
 	if err := os.Remove(sockPath); err != nil && !os.IsNotExist(err) {
-		log.Fatalf("failed to remove old socket file: %v", err)
+		return fmt.Errorf("failed to remove old socket file: %w", err)
 	}
 
-	// Create new UNIX socket listener
-	transport := os.Getenv("SKILL_TRANSPORT")
-	var lis net.Listener
-
-	switch transport {
-	case "tcp":
-		lis, err = net.Listen("tcp", "localhost:5001")
-	default:
-		lis, err = net.Listen("unix", sockPath)
-	}
+	// Create the UNIX socket or TCP listener
+	tcp := os.Getenv("SKILL_TRANSPORT") == "tcp"
+	lis, err := listen(tcp, sockPath, opts)
 	if err != nil {
-		log.Fatalf("failed to listen on socket: %v", err)
+		return fmt.Errorf("failed to listen on socket: %w", err)
 	}
 	defer lis.Close()
 
@@ -113,32 +141,175 @@ func StartRegisterSkill(path string, key string) error {
 		os.Remove(sockPath)
 	}()
 
-	os.Setenv("SKILL_TOKEN", key)
+	shutdownTracing, err := setupTracing(context.Background(), opts, manifest.Name, redactor)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Warn("Flushing traces failed", "error", err)
+		}
+	}()
+
+	registry, metrics, err := newMetrics(opts)
+	if err != nil {
+		return err
+	}
 
-	s := grpc.NewServer()
+	var policy *handler.Policy
+	if opts.Policy != "" {
+		if policy, err = handler.LoadPolicy(opts.Policy); err != nil {
+			return err
+		}
+		policy.Audit = audit
+	}
+	serverOpts, interceptors, err := serverOptions(opts, tcp, resolver, manifest, metrics, policy)
+	if err != nil {
+		return err
+	}
+	s := grpc.NewServer(serverOpts...)
 	reflection.Register(s)
 
-	srv := &handler.SkillServer{Name: "yafai-github", Description: "Github Skill for yafai", ActionsMap: manifest.Actions}
+	approvalTTL := handler.DefaultApprovalTTL
+	if manifest.ApprovalTTL != "" {
+		approvalTTL, err = time.ParseDuration(manifest.ApprovalTTL)
+		if err != nil {
+			return fmt.Errorf("invalid approval_ttl %q: %w", manifest.ApprovalTTL, err)
+		}
+	}
+
+	guard, err := handler.NewNetGuard(manifest.AllowedHosts, manifest.AllowedNetworks)
+	if err != nil {
+		return err
+	}
+
+	httpTransport, err := handler.NewTransport(manifest.HTTP, guard)
+	if err != nil {
+		return err
+	}
+	for name, action := range manifest.Actions {
+		if _, err := action.Timeouts(); err != nil {
+			return fmt.Errorf("action '%s': %w", name, err)
+		}
+		if err := action.Validate(manifest.Actions); err != nil {
+			return fmt.Errorf("action '%s': %w", name, err)
+		}
+	}
+
+	databases, err := handler.NewDatabases(manifest.Databases, manifest.Actions)
+	if err != nil {
+		return err
+	}
+	defer databases.Close()
+
+	cache, err := handler.NewResponseCache(context.Background(), manifest.CacheStore, resolver)
+	if err != nil {
+		return err
+	}
+
+	jobsConfig := manifest.Jobs
+	if jobsConfig.Dir == "" {
+		jobsConfig.Dir = "~/.yafai/jobs/" + cmp.Or(manifest.Name, "skill")
+	}
+	if jobsConfig.Dir, err = expandHome(jobsConfig.Dir); err != nil {
+		return err
+	}
+	if jobsConfig.Key == "" {
+		jobsConfig.Key = manifest.CacheStore.Key
+	}
+	jobs, err := handler.NewJobStore(context.Background(), jobsConfig, resolver)
+	if err != nil {
+		return err
+	}
+
+	var breakers *handler.CircuitBreakers
+	if manifest.CircuitBreaker != nil {
+		breakers, err = handler.NewCircuitBreakers(*manifest.CircuitBreaker)
+		if err != nil {
+			return err
+		}
+	}
+
+	srv := &handler.SkillServer{
+		Name:        manifest.Name,
+		Description: manifest.Description,
+		ActionsMap:  manifest.Actions,
+		Confirm:     manifest.Confirm,
+		Approvals:   handler.NewApprovalQueue(approvalTTL),
+		Approvers:   opts.Approvers,
+		Policy:      policy,
+		Guard:       guard,
+		Transport:   httpTransport,
+		Cache:       cache,
+		Limits:      handler.NewRateLimiter(manifest.RateLimit, manifest.Actions),
+		Breakers:    breakers,
+		Secrets:     resolver,
+		AuthToken:   manifest.AuthToken,
+		Batch:       manifest.Batch,
+		Jobs:        jobs,
+		Databases:   databases,
+		Metrics:     metrics,
+		Audit:       audit,
+		Redactor:    redactor,
+	}
 	skill.RegisterSkillServiceServer(s, srv)
+	srv.ResumeJobs()
+
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(s, healthServer)
+	readiness, err := handler.NewReadiness(healthServer, srv, manifest.Readiness)
+	if err != nil {
+		return err
+	}
+	if opts.HTTPListen != "" || opts.HTTPSocket != "" {
+		stopGateway, err := serveGateway(opts, &handler.Gateway{Server: srv, Interceptor: handler.ChainUnary(interceptors...)})
+		if err != nil {
+			return err
+		}
+		defer stopGateway()
+	}
+
+	probeCtx, stopProbes := context.WithCancel(context.Background())
+	defer stopProbes()
+	go readiness.Run(probeCtx)
+
+	if metrics != nil {
+		metrics.GRPC.InitializeMetrics(s)
+		metricsServer, err := serveMetrics(opts.MetricsAddr, registry)
+		if err != nil {
+			return err
+		}
+		defer metricsServer.Close()
+	}
 
 	go func() {
 		if err := s.Serve(lis); err != nil {
-			log.Fatalf("failed to serve: %v", err)
+			slog.Error("Failed to serve", "error", err)
+			os.Exit(1)
 		}
 	}()
 
-	log.Printf("Server listening on %v", lis.Addr())
+	slog.Info("Server listening", "addr", lis.Addr().String())
 	// Create a channel to receive OS signals
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
-	// Block until a signal is received
+	// Block until a shutdown signal is received, SIGHUP re-reads rotated secrets
 	sig := <-sigChan
-	log.Printf("Received signal: %v, initiating graceful shutdown...", sig)
+	for sig == syscall.SIGHUP {
+		resolver.Flush()
+		slog.Info("Secret cache flushed")
+		sig = <-sigChan
+	}
+	slog.Info("Received signal, initiating graceful shutdown", "signal", sig.String())
 
-	// Perform graceful shutdown
+	// Perform graceful shutdown, reporting NOT_SERVING to health watchers first
+	stopProbes()
+	healthServer.Shutdown()
 	s.GracefulStop()
-	log.Println("Server gracefully stopped")
+	slog.Info("Server gracefully stopped")
 
 	return err
 }
@@ -149,10 +320,39 @@ var rootCmd = &cobra.Command{
 	Short: "Skills Engine for YAFAI Framework",
 	Long:  ``,
 
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		path, _ := cmd.Flags().GetString("manifest")
 		key, _ := cmd.Flags().GetString("skill_key")
-		StartRegisterSkill(path, key)
+		if stdin, _ := cmd.Flags().GetBool("skill-key-stdin"); stdin {
+			var err error
+			if key, err = readSecret(secrets.SkillKey); err != nil {
+				return err
+			}
+		}
+		var opts ServeOptions
+		opts.Listen, _ = cmd.Flags().GetString("listen")
+		opts.InsecureListen, _ = cmd.Flags().GetBool("insecure-listen")
+		opts.TLSCert, _ = cmd.Flags().GetString("tls-cert")
+		opts.TLSKey, _ = cmd.Flags().GetString("tls-key")
+		opts.TLSClientCA, _ = cmd.Flags().GetString("tls-client-ca")
+		opts.AuthTokens, _ = cmd.Flags().GetString("auth-tokens")
+		opts.SocketMode, _ = cmd.Flags().GetString("socket-mode")
+		opts.SocketGroup, _ = cmd.Flags().GetString("socket-group")
+		opts.Policy, _ = cmd.Flags().GetString("policy")
+		opts.Approvers, _ = cmd.Flags().GetStringSlice("approvers")
+		opts.MetricsAddr, _ = cmd.Flags().GetString("metrics-addr")
+		opts.TraceExporter, _ = cmd.Flags().GetString("trace-exporter")
+		opts.TraceEndpoint, _ = cmd.Flags().GetString("trace-endpoint")
+		opts.TraceFile, _ = cmd.Flags().GetString("trace-file")
+		opts.HTTPListen, _ = cmd.Flags().GetString("http-listen")
+		opts.HTTPSocket, _ = cmd.Flags().GetString("http-socket")
+		opts.AuditLog, _ = cmd.Flags().GetString("audit-log")
+		opts.AuditChain, _ = cmd.Flags().GetBool("audit-chain")
+		opts.Log = logOptions(cmd)
+		opts.Log.File, _ = cmd.Flags().GetString("log-file")
+		opts.Log.MaxSize, _ = cmd.Flags().GetInt("log-max-size")
+		opts.Log.MaxBackups, _ = cmd.Flags().GetInt("log-max-backups")
+		return StartRegisterSkill(path, key, opts)
 	},
 }
 
@@ -179,16 +379,50 @@ func init() {
 	var skill_key string
 
 	rootCmd.PersistentFlags().StringVarP(&transport, "transport", "t", "unix", "Transport protocol (unix or tcp)")
-	rootCmd.PersistentFlags().StringVarP(&manifest, "manifest", "m", "", "YAFAI Skills Manifest")
-	rootCmd.PersistentFlags().StringVarP(&skill_key, "skill_key", "k", "", "YAFAI Skills key")
-
-	rootCmd.MarkPersistentFlagRequired("manifest")
-	rootCmd.MarkPersistentFlagRequired("skill_key")
-
-	// Move the transport handling to PreRun
-	rootCmd.PreRun = func(cmd *cobra.Command, args []string) {
-		slog.Info("transport", transport)
+	rootCmd.Flags().StringVarP(&manifest, "manifest", "m", "", "YAFAI Skills Manifest")
+	rootCmd.Flags().StringVarP(&skill_key, "skill_key", "k", "", "YAFAI Skills key (defaults to the skill_key secret)")
+	// A key on the command line shows in the process list and shell history
+	rootCmd.Flags().MarkDeprecated("skill_key", "store it with 'secrets set skill_key' or pass it with --skill-key-stdin")
+	rootCmd.Flags().Bool("skill-key-stdin", false, "Read the YAFAI Skills key from stdin, prompting on a terminal (defaults to the skill_key secret)")
+
+	rootCmd.PersistentFlags().String("listen", DefaultListenAddr, "Listen address for the tcp transport")
+	rootCmd.Flags().String("tls-cert", "", "Server TLS certificate (tcp transport)")
+	rootCmd.Flags().Bool("insecure-listen", false, "Serve non-loopback addresses without TLS or authentication, e.g. behind a TLS-terminating proxy")
+	rootCmd.Flags().String("tls-key", "", "Server TLS private key (tcp transport)")
+	rootCmd.Flags().String("tls-client-ca", "", "CA bundle for verifying client certificates (enables mTLS)")
+	rootCmd.Flags().String("auth-tokens", "", "YAML file mapping caller names to bearer tokens")
+	rootCmd.Flags().String("socket-mode", "", "File mode of the unix socket, e.g. 0660")
+	rootCmd.Flags().String("socket-group", "", "Group owning the unix socket")
+	rootCmd.Flags().String("metrics-addr", "", "Serve Prometheus metrics on this address, e.g. localhost:9464")
+	rootCmd.Flags().String("trace-exporter", TraceNone, "Trace exporter: otlp, stdout, file or none")
+	rootCmd.Flags().String("trace-endpoint", "", "OTLP collector address, e.g. localhost:4317 or http://collector:4317")
+	rootCmd.Flags().String("trace-file", "", "File the file trace exporter appends spans to")
+	rootCmd.PersistentFlags().String("log-level", "info", "Log level: debug, info, warn or error")
+	rootCmd.PersistentFlags().String("log-format", "text", "Log format: text or json")
+	rootCmd.Flags().String("log-file", "", "Write logs to this file instead of stderr, rotating it by size")
+	rootCmd.Flags().Int("log-max-size", DefaultLogMaxSize, "Megabytes before the log file is rotated")
+	rootCmd.Flags().Int("log-max-backups", DefaultLogMaxBackups, "Rotated log files to keep")
+	rootCmd.Flags().String("http-listen", "", "Serve the HTTP/JSON gateway on this tcp address, e.g. localhost:8080")
+	rootCmd.Flags().String("http-socket", "", "Serve the HTTP/JSON gateway on this unix socket")
+	rootCmd.Flags().String("audit-log", "", "Append an audit entry for every executed action to this file ("+defaultAuditFile+" when given without a value)")
+	rootCmd.Flags().Lookup("audit-log").NoOptDefVal = defaultAuditFile
+	rootCmd.Flags().Bool("audit-chain", false, "Hash-chain audit entries so tampering can be detected")
+	rootCmd.Flags().String("policy", "", "YAML policy restricting which callers may use which actions")
+	rootCmd.Flags().StringSlice("approvers", nil, "Callers allowed to approve held calls, e.g. unix:alice or token:ops (repeatable)")
+
+	rootCmd.MarkFlagRequired("manifest")
+	rootCmd.MarkFlagsRequiredTogether("tls-cert", "tls-key")
+
+	// Move the transport and log setup to PersistentPreRunE so subcommands see it too
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		h, err := newLogHandler(os.Stderr, logOptions(cmd))
+		if err != nil {
+			return err
+		}
+		setLogger(h)
+		slog.Debug("Using transport", "transport", transport)
 		os.Setenv("SKILL_TRANSPORT", transport)
+		return nil
 	}
 
 }
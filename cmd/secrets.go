@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strings"
+
+	"yafai-skill/secrets"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// migrateEnvKey moves a plaintext SKILL_KEY left in the .env file by earlier
+// versions into the encrypted store.
+func migrateEnvKey(envFile string, store *secrets.Store) error {
+	envMap, err := godotenv.Read(envFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	key, ok := envMap["SKILL_KEY"]
+	if !ok {
+		return nil
+	}
+
+	if err := store.Set(secrets.SkillKey, key); err != nil {
+		return err
+	}
+	delete(envMap, "SKILL_KEY")
+	delete(envMap, "SKILL_TOKEN")
+	if err := godotenv.Write(envMap, envFile); err != nil {
+		return err
+	}
+	slog.Info("Moved SKILL_KEY into the secret store", "file", envFile)
+	return nil
+}
+
+// openStore opens the local secret store under ~/.yafai.
+func openStore() (*secrets.Store, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user home directory: %w", err)
+	}
+	return secrets.OpenStore(fmt.Sprintf("%s/.yafai", homeDir)), nil
+}
+
+// secretsCmd manages the encrypted local secret store
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage secrets in the encrypted local store",
+	Long: `Manage secrets in the encrypted local store.
+
+Manifests reference stored values as secret://<name>. A running engine picks
+up changed values once its cache expires, or immediately on SIGHUP.`,
+}
+
+// readSecret prompts for the value without echo on a terminal, or reads a
+// line from stdin when it is piped. Values are never taken from arguments,
+// which other users can see in the process list and shell history keeps.
+func readSecret(name string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprintf(os.Stderr, "Value for %s: ", name)
+		value, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("reading value: %w", err)
+		}
+		return string(value), nil
+	}
+	value, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && value == "" {
+		return "", fmt.Errorf("reading value from stdin: %w", err)
+	}
+	return strings.TrimRight(value, "\r\n"), nil
+}
+
+var secretsSetCmd = &cobra.Command{
+	Use:   "set <name>",
+	Short: "Store a secret, prompting for the value or reading it from stdin",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+
+		value, err := readSecret(args[0])
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			return errors.New("secret value is empty")
+		}
+
+		if err := store.Set(args[0], value); err != nil {
+			return err
+		}
+		fmt.Printf("Stored %s\n", args[0])
+		return nil
+	},
+}
+
+var secretsGetCmd = &cobra.Command{
+	Use:   "get <name>",
+	Short: "Print a stored secret",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		value, err := store.Lookup(args[0])
+		if err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var secretsRmCmd = &cobra.Command{
+	Use:   "rm <name>",
+	Short: "Remove a stored secret",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		if err := store.Delete(args[0]); err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		fmt.Printf("Removed %s\n", args[0])
+		return nil
+	},
+}
+
+var secretsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stored secret names",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := openStore()
+		if err != nil {
+			return err
+		}
+		names, err := store.Names()
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return nil
+	},
+}
+
+func init() {
+	secretsCmd.AddCommand(secretsSetCmd, secretsGetCmd, secretsRmCmd, secretsListCmd)
+	rootCmd.AddCommand(secretsCmd)
+}
@@ -0,0 +1,335 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/user"
+	"strconv"
+	"time"
+
+	handler "yafai-skill/handler"
+	"yafai-skill/secrets"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	grpc "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// DefaultListenAddr is used by the tcp transport when --listen is not set.
+const DefaultListenAddr = "localhost:5001"
+
+// ServeOptions configures the gRPC listener.
+type ServeOptions struct {
+	Listen      string // tcp listen address
+	TLSCert     string // Server certificate and key, enables TLS on tcp
+	TLSKey      string
+	TLSClientCA string   // CA bundle verifying client certificates (mTLS)
+	AuthTokens  string   // YAML file mapping caller names to bearer tokens
+	SocketMode  string   // Octal file mode of the unix socket
+	SocketGroup string   // Group owning the unix socket
+	Policy      string   // YAML authorization policy file
+	Approvers   []string // Caller patterns allowed to approve and reject held calls
+	MetricsAddr string   // Address serving /metrics, empty disables it
+
+	TraceExporter string // otlp, stdout, file or none
+	TraceEndpoint string // OTLP collector address
+	TraceFile     string // Output of the file exporter
+
+	Log LogOptions
+
+	AuditLog   string // JSON Lines audit file, empty disables auditing
+	AuditChain bool   // Hash-chain audit entries
+
+	HTTPListen string // tcp address of the HTTP/JSON gateway
+	HTTPSocket string // unix socket of the HTTP/JSON gateway
+
+	InsecureListen bool // Serve non-loopback tcp addresses without TLS or authentication
+}
+
+// serverOptions builds the gRPC server credentials and the request ID,
+// metrics, auth and policy interceptors. The unary interceptors are also
+// returned for the HTTP gateway.
+func serverOptions(opts ServeOptions, tcp bool, resolver *secrets.Resolver, manifest *handler.APISpec, metrics *handler.Metrics, policy *handler.Policy) ([]grpc.ServerOption, []grpc.UnaryServerInterceptor, error) {
+	var serverOpts []grpc.ServerOption
+
+	if tcp {
+		if err := checkListenAddr(opts); err != nil {
+			return nil, nil, err
+		}
+	}
+	if tcp && opts.TLSCert != "" {
+		cfg, err := serverTLS(opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(cfg)))
+	} else if opts.TLSClientCA != "" && opts.HTTPListen == "" {
+		return nil, nil, fmt.Errorf("--tls-client-ca needs the tcp transport or --http-listen, with --tls-cert and --tls-key")
+	} else if !tcp && (policy != nil || len(opts.Approvers) > 0) {
+		// Peer credentials identify socket callers to the policy and approver checks
+		serverOpts = append(serverOpts, grpc.Creds(handler.PeerCredentials{}))
+	}
+
+	auth := &handler.Authenticator{Secrets: resolver}
+	if opts.AuthTokens != "" {
+		tokens, err := handler.LoadAuthTokens(opts.AuthTokens)
+		if err != nil {
+			return nil, nil, err
+		}
+		auth.Tokens = tokens
+		auth.Required = true
+	}
+	if opts.TLSClientCA != "" {
+		auth.Required = true
+	}
+
+	unary := []grpc.UnaryServerInterceptor{handler.RequestIDUnaryInterceptor()}
+	stream := []grpc.StreamServerInterceptor{handler.RequestIDStreamInterceptor()}
+	if metrics != nil {
+		unary = append(unary, metrics.GRPC.UnaryServerInterceptor())
+		stream = append(stream, metrics.GRPC.StreamServerInterceptor())
+	}
+	unary = append(unary, auth.UnaryInterceptor())
+	stream = append(stream, auth.StreamInterceptor())
+	if policy != nil {
+		unary = append(unary, policy.UnaryInterceptor(manifest.Name, manifest.Actions))
+	}
+
+	if tracingEnabled(opts) {
+		// Server spans join the caller's trace from the traceparent metadata
+		serverOpts = append(serverOpts, grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	}
+
+	serverOpts = append(serverOpts,
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	)
+	return serverOpts, unary, nil
+}
+
+// serverTLS builds the TLS config shared by the tcp listener and the gateway.
+func serverTLS(opts ServeOptions) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(opts.TLSCert, opts.TLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("loading server certificate: %w", err)
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if opts.TLSClientCA != "" {
+		pem, err := os.ReadFile(opts.TLSClientCA)
+		if err != nil {
+			return nil, fmt.Errorf("reading client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("client CA %s holds no PEM certificates", opts.TLSClientCA)
+		}
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return cfg, nil
+}
+
+// checkListenAddr refuses to serve the tcp transport to the network without
+// TLS and authentication, unless --insecure-listen says a proxy in front of
+// the engine provides them. Loopback addresses are always allowed.
+func checkListenAddr(opts ServeOptions) error {
+	addr := listenAddr(opts)
+	if isLoopback(addr) {
+		return nil
+	}
+	authenticated := opts.AuthTokens != "" || opts.TLSClientCA != ""
+	if opts.TLSCert != "" && authenticated {
+		return nil
+	}
+	if opts.InsecureListen {
+		slog.Warn("Serving without TLS or authentication on a non-loopback address", "addr", addr)
+		return nil
+	}
+	if opts.AuthTokens != "" {
+		return fmt.Errorf("--auth-tokens on %s would accept tokens in cleartext, set --tls-cert or pass --insecure-listen behind a TLS-terminating proxy", addr)
+	}
+	if opts.TLSCert != "" {
+		return fmt.Errorf("--listen %s would serve the skill to the network without authentication, set --auth-tokens or --tls-client-ca, or pass --insecure-listen behind an authenticating proxy", addr)
+	}
+	return fmt.Errorf("--listen %s would serve the skill to the network without TLS and authentication, set --tls-cert with --auth-tokens or --tls-client-ca, or pass --insecure-listen behind a proxy that provides them", addr)
+}
+
+// serveGateway starts the HTTP/JSON gateway on the configured tcp address
+// and unix socket. The returned func stops it.
+func serveGateway(opts ServeOptions, gw *handler.Gateway) (func(), error) {
+	srv := &http.Server{
+		Handler:           gw.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		ConnContext:       handler.GatewayConnContext,
+	}
+	var listeners []net.Listener
+	stop := func() {
+		srv.Close()
+		for _, lis := range listeners {
+			lis.Close()
+		}
+		if opts.HTTPSocket != "" {
+			os.Remove(opts.HTTPSocket)
+		}
+	}
+
+	if opts.HTTPListen != "" {
+		if err := checkGatewayAddr(opts); err != nil {
+			return nil, err
+		}
+		lis, err := net.Listen("tcp", opts.HTTPListen)
+		if err != nil {
+			return nil, fmt.Errorf("gateway listener: %w", err)
+		}
+		if opts.TLSCert != "" {
+			cfg, err := serverTLS(opts)
+			if err != nil {
+				lis.Close()
+				return nil, err
+			}
+			lis = tls.NewListener(lis, cfg)
+		}
+		listeners = append(listeners, lis)
+	}
+	if opts.HTTPSocket != "" {
+		if err := os.Remove(opts.HTTPSocket); err != nil && !os.IsNotExist(err) {
+			stop()
+			return nil, fmt.Errorf("removing old gateway socket: %w", err)
+		}
+		// The socket gets the same mode and group as the gRPC socket
+		lis, err := listen(false, opts.HTTPSocket, opts)
+		if err != nil {
+			stop()
+			return nil, fmt.Errorf("gateway socket: %w", err)
+		}
+		listeners = append(listeners, lis)
+	}
+
+	for _, lis := range listeners {
+		go func() {
+			if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("Gateway stopped", "error", err)
+			}
+		}()
+		slog.Info("Gateway listening", "addr", lis.Addr().String())
+	}
+	return stop, nil
+}
+
+// checkGatewayAddr refuses a gateway reachable from the network without TLS:
+// without auth tokens anyone could call the skill, and with them the tokens
+// would cross the network in cleartext.
+func checkGatewayAddr(opts ServeOptions) error {
+	if opts.TLSCert != "" || isLoopback(opts.HTTPListen) {
+		return nil
+	}
+	if opts.AuthTokens == "" {
+		return fmt.Errorf("--http-listen %s would serve the skill to the network without TLS or authentication, set --tls-cert and --auth-tokens or listen on loopback", opts.HTTPListen)
+	}
+	if !opts.InsecureListen {
+		return fmt.Errorf("--http-listen %s would accept tokens in cleartext, set --tls-cert or pass --insecure-listen behind a TLS-terminating proxy", opts.HTTPListen)
+	}
+	slog.Warn("Gateway accepting auth tokens without TLS on a non-loopback address", "addr", opts.HTTPListen)
+	return nil
+}
+
+// serveMetrics exposes the registry on /metrics until the server is closed.
+func serveMetrics(addr string, reg *prometheus.Registry) (*http.Server, error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("metrics listener: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{Registry: reg}))
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Metrics server stopped", "error", err)
+		}
+	}()
+	slog.Info("Metrics listening", "addr", lis.Addr().String())
+	return srv, nil
+}
+
+// newMetrics builds the registry and skill metrics, or nil when disabled.
+func newMetrics(opts ServeOptions) (*prometheus.Registry, *handler.Metrics, error) {
+	if opts.MetricsAddr == "" {
+		return nil, nil, nil
+	}
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	metrics, err := handler.NewMetrics(reg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return reg, metrics, nil
+}
+
+// listenAddr is the address of the tcp transport.
+func listenAddr(opts ServeOptions) string {
+	if opts.Listen == "" {
+		return DefaultListenAddr
+	}
+	return opts.Listen
+}
+
+// isLoopback reports whether the tcp address only accepts local connections.
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// listen opens the tcp or unix listener, applying the socket's permissions.
+func listen(tcp bool, sockPath string, opts ServeOptions) (net.Listener, error) {
+	if tcp {
+		return net.Listen("tcp", listenAddr(opts))
+	}
+
+	lis, err := net.Listen("unix", sockPath)
+	if err != nil {
+		return nil, err
+	}
+	if opts.SocketMode != "" {
+		mode, err := strconv.ParseUint(opts.SocketMode, 8, 32)
+		if err != nil {
+			lis.Close()
+			return nil, fmt.Errorf("invalid socket mode %q: %w", opts.SocketMode, err)
+		}
+		if err := os.Chmod(sockPath, os.FileMode(mode)); err != nil {
+			lis.Close()
+			return nil, fmt.Errorf("setting socket mode: %w", err)
+		}
+	}
+	if opts.SocketGroup != "" {
+		group, err := user.LookupGroup(opts.SocketGroup)
+		if err != nil {
+			lis.Close()
+			return nil, err
+		}
+		gid, _ := strconv.Atoi(group.Gid)
+		if err := os.Chown(sockPath, -1, gid); err != nil {
+			lis.Close()
+			return nil, fmt.Errorf("setting socket group: %w", err)
+		}
+	}
+	return lis, nil
+}
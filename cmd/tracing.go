@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"yafai-skill/redact"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Trace exporters selectable with --trace-exporter.
+const (
+	TraceNone   = "none"
+	TraceOTLP   = "otlp"
+	TraceStdout = "stdout"
+	TraceFile   = "file"
+)
+
+// tracingEnabled reports whether a trace exporter is configured.
+func tracingEnabled(opts ServeOptions) bool {
+	return opts.TraceExporter != "" && opts.TraceExporter != TraceNone
+}
+
+// setupTracing installs the global tracer provider and W3C propagators. Spans
+// are redacted by r before they are exported. The returned func flushes
+// pending spans and must be called on shutdown.
+func setupTracing(ctx context.Context, opts ServeOptions, skillName string, r *redact.Redactor) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+	if !tracingEnabled(opts) {
+		return noop, nil
+	}
+
+	var exporter sdktrace.SpanExporter
+	var out io.Closer
+	var err error
+	switch opts.TraceExporter {
+	case TraceOTLP:
+		// The endpoint falls back to OTEL_EXPORTER_OTLP_ENDPOINT, then localhost:4317
+		var clientOpts []otlptracegrpc.Option
+		switch {
+		case strings.Contains(opts.TraceEndpoint, "://"):
+			clientOpts = append(clientOpts, otlptracegrpc.WithEndpointURL(opts.TraceEndpoint))
+		case opts.TraceEndpoint != "":
+			clientOpts = append(clientOpts, otlptracegrpc.WithEndpoint(opts.TraceEndpoint))
+		}
+		exporter, err = otlptracegrpc.New(ctx, clientOpts...)
+	case TraceStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	case TraceFile:
+		if opts.TraceFile == "" {
+			return noop, fmt.Errorf("--trace-exporter file needs --trace-file")
+		}
+		f, ferr := os.OpenFile(opts.TraceFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if ferr != nil {
+			return noop, fmt.Errorf("opening trace file: %w", ferr)
+		}
+		out = f
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	default:
+		return noop, fmt.Errorf("unknown trace exporter %q, expected otlp, stdout, file or none", opts.TraceExporter)
+	}
+	if err != nil {
+		return noop, fmt.Errorf("creating trace exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(
+			attribute.String("service.name", "yafai-skill"),
+			attribute.String("skill.name", skillName),
+		),
+	)
+	if err != nil {
+		return noop, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(redact.NewSpanExporter(exporter, r)),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if out != nil {
+			out.Close()
+		}
+		return err
+	}, nil
+}
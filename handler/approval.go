@@ -0,0 +1,305 @@
+package skill
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "yafai-skill/proto"
+	"yafai-skill/redact"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Approval policies accepted by the manifest "confirm" key.
+const (
+	ConfirmRequired = "required"
+	ConfirmNever    = "never"
+	ConfirmAuto     = "auto"
+)
+
+// DefaultApprovalTTL is used when the manifest does not set approval_ttl.
+const DefaultApprovalTTL = 15 * time.Minute
+
+// ApprovalTicket is a validated action call held until it is approved or rejected.
+type ApprovalTicket struct {
+	ID        string
+	Action    *RunningAction
+	Caller    Caller // Who made the call; an approved call runs as them
+	Async     bool   // Run as a background job once approved
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// ApprovalQueue holds pending approval tickets in memory until they expire.
+type ApprovalQueue struct {
+	TTL time.Duration
+
+	mu      sync.Mutex
+	tickets map[string]*ApprovalTicket
+}
+
+// NewApprovalQueue returns an empty queue whose tickets expire after ttl.
+func NewApprovalQueue(ttl time.Duration) *ApprovalQueue {
+	if ttl <= 0 {
+		ttl = DefaultApprovalTTL
+	}
+	return &ApprovalQueue{TTL: ttl, tickets: make(map[string]*ApprovalTicket)}
+}
+
+// Add stores the caller's call and returns its ticket.
+func (q *ApprovalQueue) Add(caller Caller, action *RunningAction, async bool) *ApprovalTicket {
+	now := time.Now()
+	ticket := &ApprovalTicket{
+		ID:        uuid.New().String(),
+		Action:    action,
+		Caller:    caller,
+		Async:     async,
+		CreatedAt: now,
+		ExpiresAt: now.Add(q.TTL),
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pruneLocked(now)
+	q.tickets[ticket.ID] = ticket
+	return ticket
+}
+
+// Take removes the ticket from the queue and returns it. Expired tickets are
+// discarded and reported as such.
+func (q *ApprovalQueue) Take(id string) (*ApprovalTicket, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	ticket, ok := q.tickets[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "approval '%s' not found", id)
+	}
+	delete(q.tickets, id)
+	if time.Now().After(ticket.ExpiresAt) {
+		return nil, status.Errorf(codes.FailedPrecondition, "approval '%s' expired at %s", id, ticket.ExpiresAt.Format(time.RFC3339))
+	}
+	return ticket, nil
+}
+
+// List returns the unexpired tickets, oldest first, optionally filtered by action name.
+func (q *ApprovalQueue) List(action string) []*ApprovalTicket {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pruneLocked(time.Now())
+
+	tickets := make([]*ApprovalTicket, 0, len(q.tickets))
+	for _, t := range q.tickets {
+		if action != "" && t.Action.Name != action {
+			continue
+		}
+		tickets = append(tickets, t)
+	}
+	sort.Slice(tickets, func(i, j int) bool { return tickets[i].CreatedAt.Before(tickets[j].CreatedAt) })
+	return tickets
+}
+
+func (q *ApprovalQueue) pruneLocked(now time.Time) {
+	for id, t := range q.tickets {
+		if now.After(t.ExpiresAt) {
+			slog.Info("Approval ticket expired", "id", id, "action", t.Action.Name)
+			delete(q.tickets, id)
+		}
+	}
+}
+
+// toPB describes the planned request without any credentials. Header values
+// go through r, and without a redactor no headers are shown.
+func (t *ApprovalTicket) toPB(r *redact.Redactor) *pb.PendingApproval {
+	body, err := t.Action.payload()
+	if err != nil {
+		body = []byte(fmt.Sprintf("<unencodable body: %v>", err))
+	}
+	var headers map[string]string
+	if r != nil {
+		headers = make(map[string]string, len(t.Action.Headers))
+		for key, value := range t.Action.Headers {
+			if r.IsSensitive(key) {
+				value = redact.Mask
+			}
+			headers[key] = r.String(value)
+		}
+	}
+	return &pb.PendingApproval{
+		Id:        t.ID,
+		Action:    t.Action.Name,
+		Method:    t.Action.Method,
+		Url:       t.Action.requestURL(),
+		Body:      string(body),
+		Headers:   headers,
+		CreatedAt: timestamppb.New(t.CreatedAt),
+		ExpiresAt: timestamppb.New(t.ExpiresAt),
+	}
+}
+
+// requiresApproval resolves the action's confirm policy, falling back to the
+// manifest default. Without any policy calls run immediately.
+func (s *SkillServer) requiresApproval(actionDef *Action) bool {
+	if actionDef.Type == ActionWorkflow {
+		return s.workflowRequiresApproval(actionDef)
+	}
+	policy := actionDef.Confirm
+	if policy == "" {
+		policy = s.Confirm
+	}
+	switch strings.ToLower(policy) {
+	case ConfirmRequired:
+		return true
+	case ConfirmAuto:
+		if actionDef.GraphQL != nil {
+			return actionDef.GraphQL.isMutation()
+		}
+		if actionDef.SQL != nil {
+			return !actionDef.SQL.readOnly()
+		}
+		if actionDef.Wasm != nil {
+			return false // Modules have no network or file access
+		}
+		return !strings.EqualFold(actionDef.Method, http.MethodGet)
+	default:
+		return false
+	}
+}
+
+// holdForApproval queues the call and returns the pending ticket instead of executing it.
+func (s *SkillServer) holdForApproval(ctx context.Context, runningAction *RunningAction, async bool) (*pb.ExecuteActionResponse, error) {
+	if s.Approvals == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "action '%s' requires approval but no approval queue is configured", runningAction.Name)
+	}
+	ticket := s.Approvals.Add(CallerFromContext(ctx), runningAction, async)
+	slog.InfoContext(ctx, "Action held for approval", "id", ticket.ID, "action", runningAction.Name)
+
+	approval := ticket.toPB(s.Redactor)
+	target := approval.Method + " " + approval.Url
+	switch runningAction.Type {
+	case ActionWorkflow:
+		target = "workflow of " + runningAction.stepActions()
+	case ActionGRPC:
+		target = "call of " + approval.Url
+	case ActionSQL:
+		target = "statement on database " + runningAction.SQL.Database
+	case ActionWasm:
+		target = "module " + runningAction.Wasm.Module
+	case ActionExec:
+		target = "command " + strings.Join(append([]string{runningAction.Exec.Command}, runningAction.Args...), " ")
+	}
+	return &pb.ExecuteActionResponse{
+		Response: fmt.Sprintf("Action %s requires human approval before it runs. Approval ticket %s is pending until %s: %s",
+			runningAction.Name, ticket.ID, ticket.ExpiresAt.Format(time.RFC3339), target),
+		Approval: approval,
+	}, nil
+}
+
+// checkApprover refuses decisions from callers that are not configured as
+// approvers, so the agent holding a ticket cannot approve its own call.
+func (s *SkillServer) checkApprover(ctx context.Context) error {
+	caller := CallerFromContext(ctx)
+	if caller.Method != AuthAnonymous {
+		if s.Policy != nil && s.Policy.Admin(caller) {
+			return nil
+		}
+		if matchCaller(s.Approvers, caller) {
+			return nil
+		}
+	}
+	if s.Policy == nil && len(s.Approvers) == 0 {
+		return status.Error(codes.FailedPrecondition, "no approvers are configured, start the skill with --approvers or a --policy granting admin")
+	}
+	slog.WarnContext(ctx, "Approval decision refused", "caller", caller.String())
+	return status.Errorf(codes.PermissionDenied, "caller %s may not approve or reject calls", caller)
+}
+
+// ApproveAction RPC implementation: executes a pending call.
+func (s *SkillServer) ApproveAction(ctx context.Context, req *pb.ApproveActionRequest) (*pb.ExecuteActionResponse, error) {
+	if s.Approvals == nil {
+		return nil, status.Error(codes.FailedPrecondition, "no approval queue is configured")
+	}
+	if err := s.checkApprover(ctx); err != nil {
+		return nil, err
+	}
+	ticket, err := s.Approvals.Take(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "Action approved", "id", ticket.ID, "action", ticket.Action.Name)
+
+	start := time.Now()
+	a := ticket.Action
+	// Workflow steps are checked against the policy as the caller who asked,
+	// and a job belongs to them
+	var res *pb.ExecuteActionResponse
+	if ticket.Async {
+		res, err = s.startJob(withCaller(ctx, ticket.Caller), a)
+	} else {
+		res, err = s.runAction(withCaller(ctx, ticket.Caller), a)
+	}
+	if s.Audit != nil {
+		e := newAuditEntry(ctx, a.Name, auditArgs(a.PathParams, a.QueryParams, a.BodyParams), a, res, err, start)
+		e.ApprovalID = ticket.ID
+		s.Audit.write(ctx, e)
+	}
+	return res, err
+}
+
+// RejectAction RPC implementation: discards a pending call.
+func (s *SkillServer) RejectAction(ctx context.Context, req *pb.RejectActionRequest) (*pb.RejectActionResponse, error) {
+	if s.Approvals == nil {
+		return nil, status.Error(codes.FailedPrecondition, "no approval queue is configured")
+	}
+	if err := s.checkApprover(ctx); err != nil {
+		return nil, err
+	}
+	ticket, err := s.Approvals.Take(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "Action rejected", "id", ticket.ID, "action", ticket.Action.Name, "reason", req.Reason)
+	if s.Audit != nil {
+		a := ticket.Action
+		s.Audit.write(ctx, &AuditEntry{
+			Time:       time.Now().UTC(),
+			RequestID:  RequestIDFromContext(ctx),
+			Caller:     CallerFromContext(ctx).String(),
+			Action:     a.Name,
+			Arguments:  auditArgs(a.PathParams, a.QueryParams, a.BodyParams),
+			Method:     a.Method,
+			URL:        a.requestURL(),
+			Outcome:    AuditRejected,
+			Error:      req.Reason,
+			ApprovalID: ticket.ID,
+		})
+	}
+	return &pb.RejectActionResponse{Approval: ticket.toPB(s.Redactor)}, nil
+}
+
+// ListPendingApprovals RPC implementation.
+func (s *SkillServer) ListPendingApprovals(ctx context.Context, req *pb.ListPendingApprovalsRequest) (*pb.ListPendingApprovalsResponse, error) {
+	if s.Policy != nil || len(s.Approvers) > 0 {
+		// Held calls are for the approvers' eyes once any are configured
+		if err := s.checkApprover(ctx); err != nil {
+			return nil, err
+		}
+	}
+	res := &pb.ListPendingApprovalsResponse{}
+	if s.Approvals == nil {
+		return res, nil
+	}
+	for _, t := range s.Approvals.List(req.Action) {
+		res.Approvals = append(res.Approvals, t.toPB(s.Redactor))
+	}
+	return res, nil
+}
@@ -0,0 +1,228 @@
+package skill
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults for long-running upstream work.
+const (
+	DefaultPollInterval = 5 * time.Second
+	DefaultJobTimeout   = time.Hour
+)
+
+// AsyncPolicy handles upstreams that accept long-running work with
+// 202 Accepted and a Location header to poll until the work is done:
+//
+//	async:
+//	  poll_interval: 10s
+//	  status_field: status
+//	  done: [COMPLETE]
+//	  failed: [FAILED, CANCELED]
+//	  result_field: result.url
+//
+// Without status_field any 2xx poll response other than 202 is the result.
+type AsyncPolicy struct {
+	Always       bool     `yaml:"always"`        // Run every call as a background job, even when the caller did not ask
+	PollInterval string   `yaml:"poll_interval"` // Between polls unless the upstream sends Retry-After, defaults to 5s
+	Timeout      string   `yaml:"timeout"`       // Longest a job of this action may run, defaults to 1h
+	StatusField  string   `yaml:"status_field"`  // Dotted path of the state in poll responses
+	Done         []string `yaml:"done"`          // status_field values meaning the work succeeded
+	Failed       []string `yaml:"failed"`        // status_field values meaning it failed
+	ResultField  string   `yaml:"result_field"`  // Dotted path of a URL to fetch the result from once done
+}
+
+func (p *AsyncPolicy) validate() error {
+	if _, err := parseDuration("poll_interval", p.PollInterval, DefaultPollInterval); err != nil {
+		return err
+	}
+	if _, err := parseDuration("timeout", p.Timeout, DefaultJobTimeout); err != nil {
+		return err
+	}
+	if p.StatusField != "" && len(p.Done) == 0 {
+		return fmt.Errorf("async status_field needs done values")
+	}
+	return nil
+}
+
+func (p *AsyncPolicy) interval() time.Duration {
+	d, _ := parseDuration("poll_interval", p.PollInterval, DefaultPollInterval)
+	return d
+}
+
+// jobTimeout bounds a background job of the call.
+func (a *RunningAction) jobTimeout() time.Duration {
+	if a.Async == nil {
+		return DefaultJobTimeout
+	}
+	d, _ := parseDuration("timeout", a.Async.Timeout, DefaultJobTimeout)
+	return d
+}
+
+// awaitAccepted follows a 202 Accepted response to the outcome of the work.
+// Without a Location header there is nothing to poll and the 202 is the result.
+func (s *SkillServer) awaitAccepted(ctx context.Context, a *RunningAction, res ActionResult) (ActionResult, error) {
+	location := res.Header.Get("Location")
+	if location == "" {
+		slog.WarnContext(ctx, "Accepted response has no Location to poll", "action", a.Name)
+		return res, nil
+	}
+	u, err := resolveLocation(a.requestURL(), location)
+	if err != nil {
+		return ActionResult{}, err
+	}
+	return s.poll(ctx, a, u, retryAfter(res.Header, a.Async.interval()))
+}
+
+// poll requests the status URL until the upstream reports a terminal state.
+// Polls go through the rate limits and circuit breakers like any other call.
+func (s *SkillServer) poll(ctx context.Context, a *RunningAction, location string, wait time.Duration) (ActionResult, error) {
+	policy := a.Async
+	for {
+		s.Jobs.polling(ctx, location)
+		select {
+		case <-ctx.Done():
+			return ActionResult{}, ctx.Err()
+		case <-time.After(wait):
+		}
+
+		slog.DebugContext(ctx, "Polling upstream job", "action", a.Name)
+		res, err := s.execute(ctx, a.pollAction(location))
+		if err != nil || res.Error != nil {
+			return res, err
+		}
+		wait = retryAfter(res.Header, policy.interval())
+
+		if res.StatusCode == http.StatusAccepted {
+			if next := res.Header.Get("Location"); next != "" {
+				if location, err = resolveLocation(location, next); err != nil {
+					return ActionResult{}, err
+				}
+			}
+			continue
+		}
+		if policy.StatusField == "" {
+			return s.asyncResult(ctx, a, location, res)
+		}
+
+		var body any
+		if err := json.Unmarshal([]byte(res.Result), &body); err != nil {
+			return ActionResult{}, fmt.Errorf("poll response is not JSON: %w", err)
+		}
+		value, _ := lookupField(body, policy.StatusField)
+		state := fmt.Sprint(value)
+		switch {
+		case slices.Contains(policy.Done, state):
+			return s.asyncResult(ctx, a, location, res)
+		case slices.Contains(policy.Failed, state):
+			res.Error = fmt.Errorf("upstream job %s: %s", state, res.Result)
+			return res, nil
+		}
+	}
+}
+
+// asyncResult fetches the result from result_field when the policy names
+// one, otherwise the final poll response is the result.
+func (s *SkillServer) asyncResult(ctx context.Context, a *RunningAction, location string, res ActionResult) (ActionResult, error) {
+	if a.Async.ResultField == "" {
+		return res, nil
+	}
+	var body any
+	if err := json.Unmarshal([]byte(res.Result), &body); err != nil {
+		return ActionResult{}, fmt.Errorf("poll response is not JSON: %w", err)
+	}
+	value, ok := lookupField(body, a.Async.ResultField)
+	ref, isString := value.(string)
+	if !ok || !isString || ref == "" {
+		return ActionResult{}, fmt.Errorf("poll response has no result URL in '%s'", a.Async.ResultField)
+	}
+	u, err := resolveLocation(location, ref)
+	if err != nil {
+		return ActionResult{}, err
+	}
+	return s.execute(ctx, a.pollAction(u))
+}
+
+// pollAction is a GET of u with the call's headers and credentials. Like
+// net/http on redirects, they are only sent to the action's own origin; a URL
+// the upstream points elsewhere is fetched without them.
+func (a *RunningAction) pollAction(u string) *RunningAction {
+	p := *a
+	p.Method = http.MethodGet
+	p.BaseURL = u
+	p.QueryParams, p.BodyParams, p.PathParams = nil, nil, nil
+	p.RawBody, p.Body = nil, ""
+	p.Cache = nil
+	p.IfNoneMatch, p.IfModifiedSince = "", ""
+	if !sameOrigin(a.requestURL(), u) {
+		slog.Debug("Polling another origin without credentials", "action", a.Name)
+		p.Headers, p.Hooks = nil, nil
+		p.NoCredentials = true
+	}
+	return &p
+}
+
+// sameOrigin reports whether two URLs share scheme, host and port.
+func sameOrigin(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return ua.Scheme == ub.Scheme && canonicalAddr(ua) == canonicalAddr(ub)
+}
+
+// resolveLocation resolves a Location header against the URL it came from.
+func resolveLocation(base, location string) (string, error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	l, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("invalid Location %q: %w", location, err)
+	}
+	return b.ResolveReference(l).String(), nil
+}
+
+// retryAfter returns the upstream's Retry-After hint or def.
+func retryAfter(header http.Header, def time.Duration) time.Duration {
+	if d, ok := parseResetHeader(header.Get("Retry-After"), time.Now()); ok && d > 0 {
+		return d
+	}
+	return def
+}
+
+// lookupField follows a dotted path such as "data.items.0.id" through
+// decoded JSON.
+func lookupField(v any, path string) (any, bool) {
+	for _, part := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			var ok bool
+			if v, ok = node[part]; !ok {
+				return nil, false
+			}
+		case []any:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
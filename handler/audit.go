@@ -0,0 +1,263 @@
+package skill
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	pb "yafai-skill/proto"
+	"yafai-skill/redact"
+)
+
+// Audit outcomes.
+const (
+	AuditSuccess         = "success"
+	AuditError           = "error"
+	AuditPendingApproval = "pending_approval"
+	AuditRejected        = "rejected"
+	AuditJobStarted      = "job_started"
+	AuditCancelled       = "cancelled"
+)
+
+// AuditEntry is one line of the audit log.
+type AuditEntry struct {
+	Time       time.Time      `json:"time"`
+	RequestID  string         `json:"request_id,omitempty"`
+	Caller     string         `json:"caller"`
+	Action     string         `json:"action"`
+	Workflow   string         `json:"workflow,omitempty"`  // Set on the entries of workflow steps
+	Arguments  map[string]any `json:"arguments,omitempty"` // Path, query and body parameters, redacted
+	Method     string         `json:"method,omitempty"`
+	URL        string         `json:"url,omitempty"`
+	Status     int            `json:"status,omitempty"` // Upstream HTTP status
+	Outcome    string         `json:"outcome"`
+	Error      string         `json:"error,omitempty"`
+	DurationMS float64        `json:"duration_ms"`
+	CacheHit   bool           `json:"cache_hit,omitempty"`
+	ApprovalID string         `json:"approval_id,omitempty"`
+	JobID      string         `json:"job_id,omitempty"`
+	PrevHash   string         `json:"prev_hash,omitempty"`
+	Hash       string         `json:"hash,omitempty"` // sha256 of PrevHash and the entry, when chaining
+}
+
+// AuditLog appends entries to a JSON Lines file. When opened with chaining,
+// each entry carries the hash of the one before it, so edits and deletions
+// show up in VerifyAudit. A nil *AuditLog records nothing.
+type AuditLog struct {
+	Redactor *redact.Redactor // Masks arguments, URLs and errors before they are written
+
+	mu    sync.Mutex
+	f     *os.File
+	chain bool
+	last  string // Hash of the last entry
+}
+
+// OpenAuditLog opens or creates the audit file for appending.
+func OpenAuditLog(path string, chain bool) (*AuditLog, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("opening audit log: %w", err)
+	}
+	l := &AuditLog{f: f, chain: chain}
+	if chain {
+		// Continue the chain from the last entry already in the file
+		err := ScanAudit(f, func(_ []byte, e *AuditEntry) error {
+			l.last = e.Hash
+			return nil
+		})
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+func (l *AuditLog) Close() error {
+	if l == nil {
+		return nil
+	}
+	return l.f.Close()
+}
+
+// Append writes the entry, filling in its hashes when chaining.
+func (l *AuditLog) Append(e *AuditEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.chain {
+		e.PrevHash = l.last
+		h, err := auditHash(e)
+		if err != nil {
+			return err
+		}
+		e.Hash = h
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := l.f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := l.f.Sync(); err != nil {
+		return err
+	}
+	l.last = e.Hash
+	return nil
+}
+
+// auditHash hashes the entry without its own hash.
+func auditHash(e *AuditEntry) (string, error) {
+	c := *e
+	c.Hash = ""
+	b, err := json.Marshal(&c)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ScanAudit calls fn with each line of an audit log and its decoded entry.
+func ScanAudit(r io.Reader, fn func(line []byte, e *AuditEntry) error) error {
+	br := bufio.NewReader(r)
+	for n := 1; ; n++ {
+		line, err := br.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var e AuditEntry
+			if err := json.Unmarshal(line, &e); err != nil {
+				return fmt.Errorf("audit line %d: %w", n, err)
+			}
+			if err := fn(bytes.TrimRight(line, "\n"), &e); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// VerifyAudit checks the hash chain and returns the number of entries.
+func VerifyAudit(r io.Reader) (int, error) {
+	var n int
+	var prev string
+	err := ScanAudit(r, func(_ []byte, e *AuditEntry) error {
+		n++
+		if e.Hash == "" {
+			return fmt.Errorf("entry %d has no hash, the log is not chained", n)
+		}
+		if e.PrevHash != prev {
+			return fmt.Errorf("entry %d does not follow entry %d, entries were removed or reordered", n, n-1)
+		}
+		h, err := auditHash(e)
+		if err != nil {
+			return err
+		}
+		if h != e.Hash {
+			return fmt.Errorf("entry %d was modified", n)
+		}
+		prev = e.Hash
+		return nil
+	})
+	return n, err
+}
+
+// record writes the audit entry for a finished call.
+func (l *AuditLog) record(ctx context.Context, action string, args map[string]any, a *RunningAction, res *pb.ExecuteActionResponse, callErr error, start time.Time) {
+	if l == nil {
+		return
+	}
+	l.write(ctx, newAuditEntry(ctx, action, args, a, res, callErr, start))
+}
+
+// refused writes a rejected entry for a call refused before it was handled.
+// workflow names the workflow of a refused step.
+func (l *AuditLog) refused(ctx context.Context, req *pb.ExecuteActionRequest, workflow string, err error) {
+	if l == nil {
+		return
+	}
+	l.write(ctx, &AuditEntry{
+		Time:      time.Now().UTC(),
+		RequestID: RequestIDFromContext(ctx),
+		Caller:    CallerFromContext(ctx).String(),
+		Action:    req.Name,
+		Workflow:  workflow,
+		Arguments: auditArgs(structToMap(req.PathParams), structToMap(req.QueryParams), structToMap(req.BodyParams)),
+		Outcome:   AuditRejected,
+		Error:     err.Error(),
+	})
+}
+
+func newAuditEntry(ctx context.Context, action string, args map[string]any, a *RunningAction, res *pb.ExecuteActionResponse, callErr error, start time.Time) *AuditEntry {
+	e := &AuditEntry{
+		Time:       start.UTC(),
+		RequestID:  RequestIDFromContext(ctx),
+		Caller:     CallerFromContext(ctx).String(),
+		Action:     action,
+		Arguments:  args,
+		Outcome:    AuditSuccess,
+		DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+		CacheHit:   res.GetCacheHit(),
+	}
+	if a != nil {
+		e.Method = a.Method
+		e.URL = a.requestURL()
+		e.Status = a.StatusCode
+	}
+	switch {
+	case callErr != nil:
+		e.Outcome = AuditError
+		e.Error = callErr.Error()
+	case res.GetError() != nil:
+		e.Outcome = AuditError
+		e.Error = res.GetError().GetMessage()
+	case res.GetApproval() != nil:
+		e.Outcome = AuditPendingApproval
+		e.ApprovalID = res.GetApproval().GetId()
+	case res.GetJob() != nil:
+		e.Outcome = AuditJobStarted
+		e.JobID = res.GetJob().GetId()
+	}
+	return e
+}
+
+// write redacts and appends the entry. Failures are logged rather than
+// returned, since the action has already run.
+func (l *AuditLog) write(ctx context.Context, e *AuditEntry) {
+	if l.Redactor != nil {
+		if args, ok := l.Redactor.Value(e.Arguments).(map[string]any); ok {
+			e.Arguments = args
+		}
+		e.URL = l.Redactor.URL(e.URL)
+		e.Error = l.Redactor.String(e.Error)
+	}
+	if err := l.Append(e); err != nil {
+		slog.ErrorContext(ctx, "Could not write audit entry", "action", e.Action, "error", err)
+	}
+}
+
+// auditArgs groups the call's parameters by where they are sent.
+func auditArgs(path, query, body map[string]interface{}) map[string]any {
+	args := make(map[string]any)
+	for name, params := range map[string]map[string]interface{}{"path": path, "query": query, "body": body} {
+		if len(params) > 0 {
+			args[name] = params
+		}
+	}
+	return args
+}
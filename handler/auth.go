@@ -0,0 +1,199 @@
+package skill
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"yafai-skill/secrets"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v3"
+)
+
+// Ways a caller can be identified.
+const (
+	AuthAnonymous = "anonymous"
+	AuthToken     = "token"
+	AuthMTLS      = "mtls"
+	AuthUnix      = "unix"
+)
+
+// Caller is the authenticated identity of a gRPC caller.
+type Caller struct {
+	ID     string // Token name, certificate subject, unix user name or "anonymous"
+	Method string // How the caller was identified
+}
+
+func (c Caller) String() string {
+	return c.Method + ":" + c.ID
+}
+
+type callerKey struct{}
+
+// CallerFromContext returns the caller attached by the auth interceptor.
+func CallerFromContext(ctx context.Context) Caller {
+	if c, ok := ctx.Value(callerKey{}).(Caller); ok {
+		return c
+	}
+	return Caller{ID: AuthAnonymous, Method: AuthAnonymous}
+}
+
+// withCaller attaches the caller to ctx.
+func withCaller(ctx context.Context, c Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, c)
+}
+
+// LoadAuthTokens reads a YAML file mapping caller names to bearer tokens.
+// Tokens may be secret:// references.
+func LoadAuthTokens(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading auth tokens: %w", err)
+	}
+	tokens := make(map[string]string)
+	if err := yaml.Unmarshal(b, &tokens); err != nil {
+		return nil, fmt.Errorf("parsing auth tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// Authenticator identifies callers by static bearer token, verified client
+// certificate or unix socket peer credentials. When Required is set, calls
+// without any of them are rejected.
+type Authenticator struct {
+	Tokens   map[string]string // Caller name to token or secret:// reference
+	Secrets  *secrets.Resolver
+	Required bool
+}
+
+// Authenticate attaches the caller identity to ctx.
+func (a *Authenticator) Authenticate(ctx context.Context) (context.Context, error) {
+	if token, ok := bearerToken(ctx); ok && len(a.Tokens) > 0 {
+		name, err := a.matchToken(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		return context.WithValue(ctx, callerKey{}, Caller{ID: name, Method: AuthToken}), nil
+	}
+
+	if subject, ok := certSubject(ctx); ok {
+		return context.WithValue(ctx, callerKey{}, Caller{ID: subject, Method: AuthMTLS}), nil
+	}
+
+	// Peer credentials are verified by the kernel, so they count as authenticated
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(UnixPeerInfo); ok && !info.Anonymous {
+			return context.WithValue(ctx, callerKey{}, Caller{ID: info.Name(), Method: AuthUnix}), nil
+		}
+	}
+
+	if a.Required {
+		return nil, status.Error(codes.Unauthenticated, "a bearer token or client certificate is required")
+	}
+	return ctx, nil
+}
+
+func (a *Authenticator) matchToken(ctx context.Context, token string) (string, error) {
+	for name, ref := range a.Tokens {
+		expected, err := a.Secrets.Expand(ctx, ref)
+		if err != nil {
+			slog.Error("Could not resolve auth token", "caller", name, "error", err)
+			continue
+		}
+		if expected != "" && subtle.ConstantTimeCompare([]byte(expected), []byte(token)) == 1 {
+			return name, nil
+		}
+	}
+	return "", status.Error(codes.Unauthenticated, "invalid bearer token")
+}
+
+// UnaryInterceptor authenticates unary calls.
+func (a *Authenticator) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		authCtx, err := a.Authenticate(ctx)
+		if err != nil {
+			if isHealthCheck(info.FullMethod) {
+				return handler(ctx, req)
+			}
+			slog.WarnContext(ctx, "Rejected unauthenticated call", "method", info.FullMethod)
+			return nil, err
+		}
+		return handler(authCtx, req)
+	}
+}
+
+// StreamInterceptor authenticates streaming calls such as server reflection.
+func (a *Authenticator) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.Authenticate(ss.Context())
+		if err != nil {
+			if isHealthCheck(info.FullMethod) {
+				return handler(srv, ss)
+			}
+			slog.WarnContext(ss.Context(), "Rejected unauthenticated call", "method", info.FullMethod)
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// contextStream overrides the stream context with the authenticated one.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// isHealthCheck reports whether the method belongs to grpc.health.v1, which
+// load balancers and probes call without credentials.
+func isHealthCheck(method string) bool {
+	return strings.HasPrefix(method, "/grpc.health.v1.Health/")
+}
+
+func bearerToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	for _, v := range md.Get("authorization") {
+		// The scheme is case-insensitive (RFC 9110)
+		if scheme, token, ok := strings.Cut(v, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token), true
+		}
+	}
+	return "", false
+}
+
+// certSubject returns the common name (or first DNS/URI SAN) of a verified client certificate.
+func certSubject(ctx context.Context) (string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	cert := info.State.VerifiedChains[0][0]
+	switch {
+	case cert.Subject.CommonName != "":
+		return cert.Subject.CommonName, true
+	case len(cert.URIs) > 0:
+		return cert.URIs[0].String(), true
+	case len(cert.DNSNames) > 0:
+		return cert.DNSNames[0], true
+	}
+	return "", false
+}
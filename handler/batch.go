@@ -0,0 +1,119 @@
+package skill
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	pb "yafai-skill/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Defaults for BatchExecuteActions.
+const (
+	DefaultBatchParallelism = 8
+	DefaultBatchMaxSize     = 100
+)
+
+// BatchConfig is the batch section of the manifest.
+type BatchConfig struct {
+	MaxParallelism int `yaml:"max_parallelism"` // Calls of one batch running at once, defaults to 8
+	MaxSize        int `yaml:"max_size"`        // Calls allowed in one batch, defaults to 100
+}
+
+func (c BatchConfig) parallelism(requested int32) int {
+	limit := c.MaxParallelism
+	if limit <= 0 {
+		limit = DefaultBatchParallelism
+	}
+	if requested > 0 && int(requested) < limit {
+		return int(requested)
+	}
+	return limit
+}
+
+func (c BatchConfig) maxSize() int {
+	if c.MaxSize <= 0 {
+		return DefaultBatchMaxSize
+	}
+	return c.MaxSize
+}
+
+// BatchExecuteActions RPC implementation: runs the calls concurrently, each
+// exactly as ExecuteAction would, and returns their results in request order.
+// Failures are reported per call; the RPC itself only fails for a bad batch.
+func (s *SkillServer) BatchExecuteActions(ctx context.Context, req *pb.BatchExecuteActionsRequest) (*pb.BatchExecuteActionsResponse, error) {
+	n := len(req.Requests)
+	if n == 0 {
+		return nil, status.Error(codes.InvalidArgument, "batch has no requests")
+	}
+	if n > s.Batch.maxSize() {
+		return nil, status.Errorf(codes.InvalidArgument, "batch has %d requests, at most %d are allowed", n, s.Batch.maxSize())
+	}
+	parallelism := s.Batch.parallelism(req.Parallelism)
+	slog.InfoContext(ctx, "BatchExecuteActions called", "requests", n, "parallelism", parallelism, "fail_fast", req.FailFast)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	responses := make([]*pb.ExecuteActionResponse, n)
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	for i, item := range req.Requests {
+		if !acquire(ctx, sem) {
+			// Not started: fail_fast tripped or the caller went away
+			responses[i] = batchFailure(nil, status.Error(codes.Canceled, "not run, the batch was cancelled"))
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			res, err := s.ExecuteAction(ctx, item)
+			if err != nil {
+				if req.FailFast {
+					cancel()
+				}
+				res = batchFailure(res, err)
+			}
+			responses[i] = res
+		}()
+	}
+	wg.Wait()
+	return &pb.BatchExecuteActionsResponse{Responses: responses}, nil
+}
+
+// acquire takes a slot from sem, or reports false once ctx is done.
+func acquire(ctx context.Context, sem chan struct{}) bool {
+	select {
+	case sem <- struct{}{}:
+		if ctx.Err() != nil {
+			<-sem
+			return false
+		}
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// batchFailure records a call's error in its response, keeping any rendered
+// failure message.
+func batchFailure(res *pb.ExecuteActionResponse, err error) *pb.ExecuteActionResponse {
+	if res == nil {
+		res = &pb.ExecuteActionResponse{}
+	}
+	res.Error = responseError(err)
+	return res
+}
+
+// responseError is err as the error field of a response.
+func responseError(err error) *pb.Error {
+	st, ok := status.FromError(err)
+	if !ok {
+		st = status.FromContextError(err) // Unknown unless the call was cancelled or timed out
+	}
+	return &pb.Error{Code: pb.ErrorCode(st.Code()), Message: st.Message()}
+}
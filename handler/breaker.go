@@ -0,0 +1,256 @@
+package skill
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	pb "yafai-skill/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Circuit breaker states.
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// Circuit breaker defaults.
+const (
+	DefaultFailureThreshold = 5
+	DefaultOpenDuration     = 30 * time.Second
+)
+
+// BreakerConfig is the manifest's circuit_breaker section.
+type BreakerConfig struct {
+	Scope            string `yaml:"scope"`             // "host" (default) or "action"
+	FailureThreshold int    `yaml:"failure_threshold"` // Consecutive failures that open the breaker
+	OpenDuration     string `yaml:"open_duration"`     // How long calls fail fast before probing again
+	HalfOpenProbes   int    `yaml:"half_open_probes"`  // Calls let through while half open, defaults to 1
+}
+
+// breaker tracks one upstream host or action.
+type breaker struct {
+	state     string
+	failures  int // Consecutive failures
+	probes    int // Probes in flight while half open
+	openedAt  time.Time
+	retryAt   time.Time
+	trips     int64
+	lastError string
+}
+
+// CircuitBreakers fails calls fast while an upstream keeps failing.
+type CircuitBreakers struct {
+	scope     string
+	threshold int
+	open      time.Duration
+	probes    int
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+// NewCircuitBreakers builds the breakers from the manifest config.
+func NewCircuitBreakers(cfg BreakerConfig) (*CircuitBreakers, error) {
+	cb := &CircuitBreakers{
+		scope:     cfg.Scope,
+		threshold: cfg.FailureThreshold,
+		open:      DefaultOpenDuration,
+		probes:    cfg.HalfOpenProbes,
+		breakers:  make(map[string]*breaker),
+	}
+	if cb.scope == "" {
+		cb.scope = "host"
+	}
+	if cb.scope != "host" && cb.scope != "action" {
+		return nil, fmt.Errorf("circuit_breaker scope must be host or action, got %q", cfg.Scope)
+	}
+	if cb.threshold <= 0 {
+		cb.threshold = DefaultFailureThreshold
+	}
+	if cb.probes <= 0 {
+		cb.probes = 1
+	}
+	if cfg.OpenDuration != "" {
+		d, err := time.ParseDuration(cfg.OpenDuration)
+		if err != nil {
+			return nil, fmt.Errorf("invalid circuit_breaker open_duration %q: %w", cfg.OpenDuration, err)
+		}
+		cb.open = d
+	}
+	return cb, nil
+}
+
+// key returns the breaker the call belongs to.
+func (cb *CircuitBreakers) key(a *RunningAction) string {
+	if cb.scope == "action" {
+		return a.Name
+	}
+	u, err := url.Parse(a.requestURL())
+	if err != nil || u.Host == "" {
+		return a.Name
+	}
+	return u.Host
+}
+
+// Allow reports whether the call may go out. It returns UNAVAILABLE while the
+// breaker is open, and lets a limited number of probes through once it has
+// been open for the configured duration.
+func (cb *CircuitBreakers) Allow(a *RunningAction) (string, error) {
+	key := cb.key(a)
+	now := time.Now()
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	b, ok := cb.breakers[key]
+	if !ok {
+		b = &breaker{state: BreakerClosed}
+		cb.breakers[key] = b
+	}
+
+	switch b.state {
+	case BreakerOpen:
+		if now.Before(b.retryAt) {
+			return key, status.Errorf(codes.Unavailable,
+				"%s is unavailable: circuit breaker for %s opened after %d consecutive failures (last: %s), retrying after %s",
+				a.Name, key, b.failures, b.lastError, b.retryAt.Format(time.RFC3339))
+		}
+		b.state = BreakerHalfOpen
+		b.probes = 0
+		slog.Info("Circuit breaker half open", "key", key)
+		fallthrough
+	case BreakerHalfOpen:
+		if b.probes >= cb.probes {
+			return key, status.Errorf(codes.Unavailable,
+				"%s is unavailable: circuit breaker for %s is probing the upstream, try again shortly", a.Name, key)
+		}
+		b.probes++
+	}
+	return key, nil
+}
+
+// Record updates the breaker with the outcome of a call let through by Allow.
+func (cb *CircuitBreakers) Record(key string, failure error) {
+	now := time.Now()
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	b := cb.breakers[key]
+	if b == nil {
+		return
+	}
+	if b.state == BreakerHalfOpen {
+		b.probes--
+	}
+
+	if failure == nil {
+		if b.state != BreakerClosed {
+			slog.Info("Circuit breaker closed", "key", key)
+		}
+		b.state = BreakerClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	b.lastError = failure.Error()
+	if b.state == BreakerHalfOpen || b.failures >= cb.threshold {
+		if b.state != BreakerOpen {
+			b.trips++
+		}
+		b.state = BreakerOpen
+		b.openedAt = now
+		b.retryAt = now.Add(cb.open)
+		slog.Warn("Circuit breaker opened", "key", key, "failures", b.failures, "retry_at", b.retryAt.Format(time.RFC3339))
+	}
+}
+
+// Release gives back a half-open probe slot for a call that never reached the upstream.
+func (cb *CircuitBreakers) Release(key string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if b := cb.breakers[key]; b != nil && b.state == BreakerHalfOpen {
+		b.probes--
+	}
+}
+
+// state returns the breaker's current state.
+func (cb *CircuitBreakers) state(key string) string {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if b := cb.breakers[key]; b != nil {
+		return b.state
+	}
+	return BreakerClosed
+}
+
+// upstreamFailure returns the error that counts against the breaker, if any:
+// transport errors and 5xx responses, not client errors.
+func upstreamFailure(res ActionResult) error {
+	if res.Error == nil {
+		return nil
+	}
+	if st, ok := status.FromError(res.Error); ok {
+		// Caller errors, such as mapped exit codes or GraphQL errors, say nothing about the upstream's health
+		switch st.Code() {
+		case codes.InvalidArgument, codes.NotFound, codes.AlreadyExists, codes.PermissionDenied,
+			codes.FailedPrecondition, codes.OutOfRange, codes.Unauthenticated:
+			return nil
+		}
+	}
+	if res.StatusCode != 0 && res.StatusCode < http.StatusInternalServerError {
+		return nil
+	}
+	if res.StatusCode != 0 {
+		return fmt.Errorf("HTTP %d", res.StatusCode)
+	}
+	return res.Error
+}
+
+// Snapshot describes every breaker, sorted by key.
+func (cb *CircuitBreakers) Snapshot() []*pb.CircuitBreaker {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	out := make([]*pb.CircuitBreaker, 0, len(cb.breakers))
+	for key, b := range cb.breakers {
+		state := b.state
+		if state == BreakerOpen && time.Now().After(b.retryAt) {
+			state = BreakerHalfOpen
+		}
+		s := &pb.CircuitBreaker{
+			Key:                 key,
+			State:               state,
+			ConsecutiveFailures: int32(b.failures),
+			Trips:               b.trips,
+			LastError:           b.lastError,
+		}
+		if !b.openedAt.IsZero() {
+			s.OpenedAt = timestamppb.New(b.openedAt)
+			s.RetryAt = timestamppb.New(b.retryAt)
+		}
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// GetCircuitBreakers RPC implementation.
+func (s *SkillServer) GetCircuitBreakers(ctx context.Context, req *pb.GetCircuitBreakersRequest) (*pb.GetCircuitBreakersResponse, error) {
+	if s.Breakers == nil {
+		return &pb.GetCircuitBreakersResponse{}, nil
+	}
+	return &pb.GetCircuitBreakersResponse{Breakers: s.Breakers.Snapshot()}, nil
+}
@@ -0,0 +1,437 @@
+package skill
+
+import (
+	"cmp"
+	"container/list"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "yafai-skill/proto"
+	"yafai-skill/secrets"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Default response cache limits.
+const (
+	DefaultCacheEntries = 1000
+	DefaultCacheBytes   = 32 << 20
+)
+
+// CachePolicy enables response caching for an action.
+type CachePolicy struct {
+	TTL        string   `yaml:"ttl"`        // How long a response is served without contacting the upstream
+	KeyFields  []string `yaml:"key_fields"` // Arguments that identify a response; defaults to all of them
+	Revalidate bool     `yaml:"revalidate"` // Honour Cache-Control and revalidate stale entries with ETag/Last-Modified
+	Shared     bool     `yaml:"shared"`     // Serve responses cached for one caller to every caller
+}
+
+// CacheStoreConfig is the manifest's cache_store section.
+type CacheStoreConfig struct {
+	MaxEntries int    `yaml:"max_entries"`
+	MaxBytes   int64  `yaml:"max_bytes"`
+	Dir        string `yaml:"dir"` // Optional directory persisting entries across restarts
+	Key        string `yaml:"key"` // Base64 32 byte key encrypting persisted entries, e.g. secret://cache_key; required with dir
+}
+
+// CacheEntry is a cached upstream response.
+type CacheEntry struct {
+	Key          string    `json:"key"`
+	Action       string    `json:"action"`
+	Body         string    `json:"body"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Expires      time.Time `json:"expires"`
+}
+
+func (e *CacheEntry) size() int64 {
+	return int64(len(e.Key) + len(e.Body) + len(e.ETag) + len(e.LastModified))
+}
+
+func (e *CacheEntry) revalidatable() bool {
+	return e.ETag != "" || e.LastModified != ""
+}
+
+// ResponseCache is an in-memory LRU of upstream responses, optionally mirrored
+// to disk. Entries on disk are encrypted with AES-256-GCM.
+type ResponseCache struct {
+	maxEntries int
+	maxBytes   int64
+	dir        string
+	gcm        cipher.AEAD
+
+	mu      sync.Mutex
+	order   *list.List // Front is most recently used
+	entries map[string]*list.Element
+	bytes   int64
+}
+
+// NewResponseCache returns an empty cache, loading persisted entries from cfg.Dir when set.
+func NewResponseCache(ctx context.Context, cfg CacheStoreConfig, resolver *secrets.Resolver) (*ResponseCache, error) {
+	c := &ResponseCache{
+		maxEntries: cfg.MaxEntries,
+		maxBytes:   cfg.MaxBytes,
+		dir:        cfg.Dir,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+	if c.maxEntries <= 0 {
+		c.maxEntries = DefaultCacheEntries
+	}
+	if c.maxBytes <= 0 {
+		c.maxBytes = DefaultCacheBytes
+	}
+	if c.dir != "" {
+		if cfg.Key == "" {
+			return nil, errors.New("cache_store.dir needs cache_store.key to encrypt the entries it keeps")
+		}
+		gcm, err := storeCipher(ctx, "cache_store.key", cfg.Key, resolver)
+		if err != nil {
+			return nil, err
+		}
+		c.gcm = gcm
+		if err := os.MkdirAll(c.dir, 0700); err != nil {
+			return nil, fmt.Errorf("creating cache directory: %w", err)
+		}
+		c.load()
+	}
+	return c, nil
+}
+
+// Get returns the entry for key and whether it is still fresh. Stale entries
+// are only returned when they can be revalidated.
+func (c *ResponseCache) Get(key string) (*CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*CacheEntry)
+	if time.Now().Before(entry.Expires) {
+		c.order.MoveToFront(el)
+		return entry, true
+	}
+	if entry.revalidatable() {
+		return entry, false
+	}
+	c.removeLocked(el)
+	return nil, false
+}
+
+// Put stores entry, evicting the least recently used entries beyond the limits.
+func (c *ResponseCache) Put(entry *CacheEntry) {
+	if entry.size() > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[entry.Key]; ok {
+		c.removeLocked(el)
+	}
+	c.entries[entry.Key] = c.order.PushFront(entry)
+	c.bytes += entry.size()
+	c.persist(entry)
+
+	for c.order.Len() > c.maxEntries || c.bytes > c.maxBytes {
+		c.removeLocked(c.order.Back())
+	}
+}
+
+// Purge removes the entries for action, or every entry when action is empty,
+// and returns how many were removed.
+func (c *ResponseCache) Purge(action string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	purged := 0
+	for _, el := range c.entries {
+		if action == "" || el.Value.(*CacheEntry).Action == action {
+			c.removeLocked(el)
+			purged++
+		}
+	}
+	return purged
+}
+
+func (c *ResponseCache) removeLocked(el *list.Element) {
+	entry := el.Value.(*CacheEntry)
+	c.order.Remove(el)
+	delete(c.entries, entry.Key)
+	c.bytes -= entry.size()
+	if c.dir != "" {
+		os.Remove(c.path(entry.Key))
+	}
+}
+
+// storeCipher builds the cipher of a disk store from the base64 key ref,
+// which name gives in errors.
+func storeCipher(ctx context.Context, name, ref string, resolver *secrets.Resolver) (cipher.AEAD, error) {
+	encoded, err := resolver.Expand(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("%s must be a base64 encoded 32 byte key", name)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plain with a random nonce, which the result starts with.
+func seal(gcm cipher.AEAD, plain []byte) ([]byte, error) {
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plain, nil), nil
+}
+
+// unseal decrypts what seal returned.
+func unseal(gcm cipher.AEAD, b []byte) ([]byte, error) {
+	size := gcm.NonceSize()
+	if len(b) < size {
+		return nil, errors.New("encrypted file is corrupt")
+	}
+	return gcm.Open(nil, b[:size], b[size:], nil)
+}
+
+func (c *ResponseCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".enc")
+}
+
+func (c *ResponseCache) persist(entry *CacheEntry) {
+	if c.dir == "" {
+		return
+	}
+	b, err := json.Marshal(entry)
+	if err == nil {
+		if b, err = seal(c.gcm, b); err == nil {
+			err = os.WriteFile(c.path(entry.Key), b, 0600)
+		}
+	}
+	if err != nil {
+		slog.Warn("Could not persist cache entry", "action", entry.Action, "error", err)
+	}
+}
+
+// load reads persisted entries, dropping ones that can no longer be used or
+// decrypted. Plaintext entries left by earlier versions are removed.
+func (c *ResponseCache) load() {
+	if plain, err := filepath.Glob(filepath.Join(c.dir, "*.json")); err == nil {
+		for _, f := range plain {
+			os.Remove(f)
+		}
+	}
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.enc"))
+	if err != nil {
+		return
+	}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		var entry CacheEntry
+		if err == nil {
+			err = c.decode(b, &entry)
+		}
+		if err != nil || (time.Now().After(entry.Expires) && !entry.revalidatable()) {
+			os.Remove(f)
+			continue
+		}
+		c.entries[entry.Key] = c.order.PushBack(&entry)
+		c.bytes += entry.size()
+	}
+	for c.order.Len() > c.maxEntries || c.bytes > c.maxBytes {
+		c.removeLocked(c.order.Back())
+	}
+}
+
+func (c *ResponseCache) decode(b []byte, entry *CacheEntry) error {
+	plain, err := unseal(c.gcm, b)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(plain, entry)
+}
+
+// cacheKey identifies a call by action name and the policy's key arguments,
+// and unless the policy is shared, by the caller.
+func cacheKey(ctx context.Context, a *RunningAction) (string, error) {
+	args := map[string]interface{}{}
+	for _, params := range []map[string]interface{}{a.PathParams, a.QueryParams, a.BodyParams} {
+		for k, v := range params {
+			args[k] = v
+		}
+	}
+	if a.RawBody != nil {
+		args["$body"] = a.RawBody
+	}
+	if len(a.Cache.KeyFields) > 0 {
+		selected := make(map[string]interface{}, len(a.Cache.KeyFields))
+		for _, f := range a.Cache.KeyFields {
+			selected[f] = args[f]
+		}
+		args = selected
+	}
+	if !a.Cache.Shared {
+		args["$caller"] = CallerFromContext(ctx).String()
+	}
+	// encoding/json sorts map keys, so equal arguments always give the same key
+	b, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return a.Name + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// cacheControl returns how long a response may be cached according to its
+// Cache-Control header, and whether it may be stored at all. no-store and
+// private responses are never stored.
+func cacheControl(header http.Header, ttl time.Duration) (time.Duration, bool) {
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-store", directive == "private":
+			return 0, false
+		case directive == "no-cache":
+			ttl = 0
+		case strings.HasPrefix(directive, "max-age="):
+			if secs, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age=")); err == nil {
+				ttl = time.Duration(secs) * time.Second
+			}
+		}
+	}
+	return ttl, true
+}
+
+// fetch returns the upstream result for the call, serving and filling the
+// response cache when the action has a cache policy.
+func (s *SkillServer) fetch(ctx context.Context, a *RunningAction) (ActionResult, bool, error) {
+	if a.Cache == nil || s.Cache == nil {
+		res, err := s.execute(ctx, a)
+		return res, false, err
+	}
+
+	key, err := cacheKey(ctx, a)
+	if err != nil {
+		return ActionResult{}, false, fmt.Errorf("building cache key: %w", err)
+	}
+	entry, fresh := s.Cache.Get(key)
+	if fresh {
+		slog.InfoContext(ctx, "Cache hit", "action", a.Name)
+		s.Metrics.cacheLookup(a.Name, "hit")
+		return ActionResult{Result: entry.Body, StatusCode: http.StatusOK}, true, nil
+	}
+	if entry != nil && a.Cache.Revalidate {
+		a.IfNoneMatch = entry.ETag
+		a.IfModifiedSince = entry.LastModified
+	}
+
+	res, err := s.execute(ctx, a)
+	if err != nil || res.Error != nil {
+		s.Metrics.cacheLookup(a.Name, "miss")
+		return res, false, err
+	}
+
+	hit := false
+	if res.StatusCode == http.StatusNotModified && entry != nil {
+		slog.InfoContext(ctx, "Cache revalidated", "action", a.Name)
+		res.Result = entry.Body
+		hit = true
+		s.Metrics.cacheLookup(a.Name, "revalidated")
+	} else {
+		s.Metrics.cacheLookup(a.Name, "miss")
+	}
+	s.store(key, a, res, entry)
+	return res, hit, nil
+}
+
+// cacheableStatus reports whether a result with the given upstream status is
+// final and may be served from the cache. A 202 is only an acknowledgement
+// that must still be polled, and redirects are not the resource itself.
+// Actions without an HTTP status (exec, sql, grpc) are always final.
+func cacheableStatus(code int, previous *CacheEntry) bool {
+	switch {
+	case code == 0:
+		return true
+	case code == http.StatusNotModified:
+		return previous != nil
+	case code == http.StatusAccepted:
+		return false
+	default:
+		return code >= http.StatusOK && code < http.StatusMultipleChoices
+	}
+}
+
+// store caches a successful result according to the action's policy.
+func (s *SkillServer) store(key string, a *RunningAction, res ActionResult, previous *CacheEntry) {
+	if !cacheableStatus(res.StatusCode, previous) {
+		return
+	}
+	ttl, _ := parseDuration("cache ttl", a.Cache.TTL, 0) // Checked by Validate
+
+	entry := &CacheEntry{Key: key, Action: a.Name, Body: res.Result}
+	if res.Header != nil {
+		// no-store and private are honoured even without revalidation;
+		// max-age and no-cache only shorten the ttl when revalidating
+		capped, ok := cacheControl(res.Header, ttl)
+		if !ok {
+			return
+		}
+		if a.Cache.Revalidate {
+			ttl = capped
+			entry.ETag = res.Header.Get("ETag")
+			entry.LastModified = res.Header.Get("Last-Modified")
+		}
+	}
+	if previous != nil && res.StatusCode == http.StatusNotModified {
+		// A 304 may omit validators, keep the ones already known
+		entry.ETag = cmp.Or(entry.ETag, previous.ETag)
+		entry.LastModified = cmp.Or(entry.LastModified, previous.LastModified)
+	}
+	if ttl <= 0 && !entry.revalidatable() {
+		return
+	}
+	entry.Expires = time.Now().Add(ttl)
+	s.Cache.Put(entry)
+}
+
+// PurgeCache RPC implementation.
+func (s *SkillServer) PurgeCache(ctx context.Context, req *pb.PurgeCacheRequest) (*pb.PurgeCacheResponse, error) {
+	if s.Cache == nil {
+		return &pb.PurgeCacheResponse{}, nil
+	}
+	if req.Action != "" {
+		if _, ok := s.ActionsMap[req.Action]; !ok {
+			return nil, status.Errorf(codes.NotFound, "action '%s' not found", req.Action)
+		}
+	}
+	purged := s.Cache.Purge(req.Action)
+	slog.InfoContext(ctx, "Cache purged", "action", req.Action, "entries", purged)
+	return &pb.PurgeCacheResponse{Purged: int32(purged)}, nil
+}
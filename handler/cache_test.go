@@ -0,0 +1,41 @@
+package skill
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestStoreCachesOnlyFinalResponses(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		header     http.Header
+		revalidate bool
+		stored     bool
+	}{
+		{name: "ok", status: http.StatusOK, stored: true},
+		{name: "no http status", status: 0, stored: true},
+		{name: "accepted", status: http.StatusAccepted},
+		{name: "redirect", status: http.StatusFound},
+		{name: "not modified without entry", status: http.StatusNotModified},
+		{name: "no-store", status: http.StatusOK, header: http.Header{"Cache-Control": {"no-store"}}},
+		{name: "private", status: http.StatusOK, header: http.Header{"Cache-Control": {"private, max-age=60"}}},
+		{name: "no-store with revalidate", status: http.StatusOK, header: http.Header{"Cache-Control": {"no-store"}}, revalidate: true},
+		{name: "max-age ignored without revalidate", status: http.StatusOK, header: http.Header{"Cache-Control": {"max-age=0"}}, stored: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, err := NewResponseCache(context.Background(), CacheStoreConfig{}, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := &SkillServer{Cache: cache}
+			a := &RunningAction{Name: "get", Cache: &CachePolicy{TTL: "1m", Revalidate: tt.revalidate}}
+			s.store("key", a, ActionResult{Result: "body", StatusCode: tt.status, Header: tt.header}, nil)
+			if _, fresh := cache.Get("key"); fresh != tt.stored {
+				t.Errorf("stored = %v, want %v", fresh, tt.stored)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+package skill
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	pb "yafai-skill/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ActionExec runs a local program instead of calling an API.
+const ActionExec = "exec"
+
+// Limits on what an exec action keeps of the program's output.
+const (
+	DefaultExecMaxOutput = 1 << 20
+	execMaxStderr        = 64 << 10
+)
+
+// ExecSpec is the exec section of an exec action:
+//
+//	exec:
+//	  command: kubectl
+//	  args: [get, pods, -n, "{{ .namespace }}", -o, json]
+//	  env: [KUBECONFIG]
+//	  exit_codes: {1: NOT_FOUND}
+//
+// Each argument is a template over the call's params and is passed to the
+// program as is; nothing goes through a shell. Arguments that render empty
+// are left out, so optional flags can be written as
+// "{{ with .selector }}--selector={{ . }}{{ end }}".
+type ExecSpec struct {
+	Command   string            `yaml:"command"`    // Program path, or a name looked up in PATH at startup
+	Args      []string          `yaml:"args"`       // One template per argument
+	Dir       string            `yaml:"dir"`        // Working directory, defaults to the skill's
+	Env       []string          `yaml:"env"`        // Variables passed through from the skill's environment
+	EnvValues map[string]string `yaml:"env_values"` // Variables set for the program; secret:// references allowed
+	MaxOutput int64             `yaml:"max_output"` // Bytes of stdout allowed, defaults to 1 MiB; more fails the call
+	Output    string            `yaml:"output"`     // "json", "text", or empty to use JSON when stdout parses
+	ExitCodes map[int]string    `yaml:"exit_codes"` // Exit status to error code, e.g. 2: INVALID_ARGUMENT; others are UNKNOWN
+
+	path string // Command resolved at startup
+}
+
+func (e *ExecSpec) validate() error {
+	if e == nil || e.Command == "" {
+		return fmt.Errorf("exec action needs exec.command")
+	}
+	path, err := exec.LookPath(e.Command)
+	if err != nil {
+		return fmt.Errorf("exec command: %w", err)
+	}
+	e.path = path
+	for i, arg := range e.Args {
+		if _, err := template.New("arg").Parse(arg); err != nil {
+			return fmt.Errorf("exec argument %d: %w", i+1, err)
+		}
+	}
+	switch e.Output {
+	case "", "json", "text":
+	default:
+		return fmt.Errorf("exec output must be json or text, got %q", e.Output)
+	}
+	for status, name := range e.ExitCodes {
+		if _, ok := pb.ErrorCode_value[name]; !ok {
+			return fmt.Errorf("exec exit code %d: unknown error code %q", status, name)
+		}
+	}
+	return nil
+}
+
+// renderArgs builds the program's arguments from the call's params. Only the
+// template may make an argument start with "-", never a param value, so
+// params cannot inject options.
+func (e *ExecSpec) renderArgs(params map[string]any) ([]string, error) {
+	defused := defuseDashes(params)
+	args := make([]string, 0, len(e.Args))
+	for i, text := range e.Args {
+		tmpl, err := template.New("arg").Parse(text)
+		if err != nil {
+			return nil, err
+		}
+		arg, err := renderArg(tmpl, params)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "argument %d: %v", i+1, err)
+		}
+		switch {
+		case arg == "":
+			continue
+		case strings.Contains(arg, "<no value>"):
+			return nil, status.Errorf(codes.InvalidArgument, "argument %d uses a param that was not given", i+1)
+		case strings.HasPrefix(arg, "-"):
+			// Still a dash without dashes in the values: it is the template's
+			if literal, err := renderArg(tmpl, defused); err != nil || !strings.HasPrefix(literal, "-") {
+				return nil, status.Errorf(codes.InvalidArgument, "argument %d may not start with '-'", i+1)
+			}
+		}
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+func renderArg(tmpl *template.Template, params map[string]any) (string, error) {
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, params); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// defuseDashes returns params with each value that would print with a
+// leading "-" replaced by one that does not, down through nested objects and
+// lists so that {{ .opts.name }} and {{ index .files 0 }} are covered too.
+func defuseDashes(params map[string]any) map[string]any {
+	defused := make(map[string]any, len(params))
+	for name, value := range params {
+		defused[name] = defuseValue(value)
+	}
+	return defused
+}
+
+func defuseValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return defuseDashes(v)
+	case []any:
+		defused := make([]any, len(v))
+		for i, item := range v {
+			defused[i] = defuseValue(item)
+		}
+		return defused
+	}
+	if s := fmt.Sprint(value); strings.HasPrefix(s, "-") {
+		return "_" + s[1:]
+	}
+	return value
+}
+
+// commandEnv is the program's environment: only the allowed variables of the
+// skill's own environment and the configured values. The slice is never nil,
+// as a nil cmd.Env would hand the program the skill's whole environment.
+func (a *RunningAction) commandEnv(ctx context.Context) ([]string, error) {
+	env := []string{}
+	for _, name := range a.Exec.Env {
+		if v, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+v)
+		}
+	}
+	for name, value := range a.Exec.EnvValues {
+		v, err := a.Secrets.Expand(ctx, value)
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, name+"="+v)
+	}
+	return env, nil
+}
+
+// cappedBuffer keeps up to limit bytes and calls overflow once more arrive.
+// The buffer is not embedded so io.Copy cannot bypass Write via ReadFrom.
+type cappedBuffer struct {
+	buf      bytes.Buffer
+	limit    int64
+	overflow func()
+
+	once     sync.Once
+	exceeded bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - int64(b.buf.Len()); int64(len(p)) > room {
+		b.buf.Write(p[:max(room, 0)])
+		b.once.Do(func() {
+			b.exceeded = true
+			if b.overflow != nil {
+				b.overflow()
+			}
+		})
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+// runCommand is Execute for exec actions.
+func (a *RunningAction) runCommand(ctx context.Context, resultChan chan<- ActionResult) {
+	spec := a.Exec
+	ctx, cancel := context.WithTimeout(ctx, a.Timeouts.Total)
+	defer cancel()
+
+	env, err := a.commandEnv(ctx)
+	if err != nil {
+		resultChan <- ActionResult{Error: err}
+		return
+	}
+	limit := spec.MaxOutput
+	if limit <= 0 {
+		limit = DefaultExecMaxOutput
+	}
+	stdout := &cappedBuffer{limit: limit, overflow: cancel}
+	stderr := &cappedBuffer{limit: execMaxStderr}
+
+	cmd := exec.CommandContext(ctx, spec.path, a.Args...)
+	cmd.Dir = spec.Dir
+	cmd.Env = env
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	cmd.WaitDelay = time.Second
+
+	slog.DebugContext(ctx, "Running command", "action", a.Name, "command", spec.path, "args", a.Args)
+	start := time.Now()
+	err = cmd.Run()
+	slog.DebugContext(ctx, "Command finished", "action", a.Name, "duration", time.Since(start), "error", err)
+
+	var exitErr *exec.ExitError
+	switch {
+	case stdout.exceeded:
+		err = status.Errorf(codes.ResourceExhausted, "command output exceeded %d bytes", limit)
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		err = status.Errorf(codes.DeadlineExceeded, "command timed out after %s", a.Timeouts.Total)
+	case errors.As(err, &exitErr):
+		code := codes.Unknown
+		if name, ok := spec.ExitCodes[exitErr.ExitCode()]; ok {
+			code = codes.Code(pb.ErrorCode_value[name])
+		}
+		err = status.Errorf(code, "command exited with status %d: %s", exitErr.ExitCode(), strings.TrimSpace(stderr.buf.String()))
+	case err != nil:
+		err = status.Errorf(codes.FailedPrecondition, "could not run command: %v", err)
+	}
+	if err != nil {
+		resultChan <- ActionResult{Error: err}
+		return
+	}
+
+	out := stdout.buf.String()
+	switch spec.Output {
+	case "json":
+		if !json.Valid(stdout.buf.Bytes()) {
+			resultChan <- ActionResult{Error: status.Error(codes.Internal, "command output is not JSON")}
+			return
+		}
+	case "text":
+		// Templates see the text as .result, like any non-JSON response
+		b, _ := json.Marshal(map[string]string{"result": out})
+		out = string(b)
+	}
+	resultChan <- ActionResult{Result: out}
+}
@@ -0,0 +1,131 @@
+package skill
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRenderArgsRefusesDashesFromParams(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		params map[string]any
+		want   []string
+		code   codes.Code
+	}{
+		{
+			name:   "template dashes",
+			args:   []string{"get", "-n", "{{ .ns }}", "{{ with .sel }}--selector={{ . }}{{ end }}"},
+			params: map[string]any{"ns": "default", "sel": "app=web"},
+			want:   []string{"get", "-n", "default", "--selector=app=web"},
+		},
+		{
+			name:   "empty argument left out",
+			args:   []string{"get", "{{ with .sel }}--selector={{ . }}{{ end }}"},
+			params: map[string]any{},
+			want:   []string{"get"},
+		},
+		{
+			name:   "dash inside a value",
+			args:   []string{"{{ .name }}"},
+			params: map[string]any{"name": "my-pod"},
+			want:   []string{"my-pod"},
+		},
+		{
+			name:   "top-level param",
+			args:   []string{"get", "{{ .ns }}"},
+			params: map[string]any{"ns": "--all-namespaces"},
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "negative number",
+			args:   []string{"{{ .n }}"},
+			params: map[string]any{"n": -1.0},
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "list item",
+			args:   []string{"cat", "{{ index .files 0 }}"},
+			params: map[string]any{"files": []any{"--output=/etc/passwd"}},
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "nested object field",
+			args:   []string{"{{ .opts.name }}"},
+			params: map[string]any{"opts": map[string]any{"name": "-rf"}},
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "object in a list",
+			args:   []string{"{{ (index .items 1).id }}"},
+			params: map[string]any{"items": []any{map[string]any{"id": "a"}, map[string]any{"id": "-x"}}},
+			code:   codes.InvalidArgument,
+		},
+		{
+			name:   "param not given",
+			args:   []string{"{{ .missing.field }}"},
+			params: map[string]any{"missing": map[string]any{}},
+			code:   codes.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := &ExecSpec{Args: tt.args}
+			got, err := spec.renderArgs(tt.params)
+			if tt.code != codes.OK {
+				if status.Code(err) != tt.code {
+					t.Fatalf("renderArgs = %q, %v; want code %s", got, err, tt.code)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("renderArgs = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandEnvOnlyPassesConfiguredVariables(t *testing.T) {
+	t.Setenv("SKILL_TEST_PASSED", "yes")
+	t.Setenv("SKILL_TEST_HIDDEN", "secret")
+	tests := []struct {
+		name   string
+		env    []string
+		values map[string]string
+		want   []string
+	}{
+		{name: "nothing configured", want: []string{}},
+		{name: "unset variable", env: []string{"SKILL_TEST_UNSET"}, want: []string{}},
+		{name: "passed through", env: []string{"SKILL_TEST_PASSED"}, want: []string{"SKILL_TEST_PASSED=yes"}},
+		{name: "set value", values: map[string]string{"MODE": "ro"}, want: []string{"MODE=ro"}},
+		{
+			name:   "both",
+			env:    []string{"SKILL_TEST_PASSED"},
+			values: map[string]string{"MODE": "ro"},
+			want:   []string{"SKILL_TEST_PASSED=yes", "MODE=ro"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &RunningAction{Exec: &ExecSpec{Env: tt.env, EnvValues: tt.values}}
+			got, err := a.commandEnv(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			// A nil cmd.Env would hand the program the skill's whole environment
+			if got == nil {
+				t.Fatal("commandEnv returned a nil slice")
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("commandEnv = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package skill
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+
+	pb "yafai-skill/proto"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxGatewayBody bounds ExecuteAction request bodies.
+const maxGatewayBody = 4 << 20
+
+// gatewayHeaders are the HTTP headers passed on to the interceptors as metadata.
+var gatewayHeaders = []string{"authorization", RequestIDHeader, "traceparent", "tracestate", "baggage"}
+
+// Gateway serves SkillService as JSON over HTTP:
+//
+//	GET  /v1/actions?task=...         GetActions
+//	POST /v1/actions/{name}:execute   ExecuteAction
+//	POST /v1/actions:batchExecute     BatchExecuteActions
+//	GET  /v1/jobs?action=&state=      ListJobs
+//	GET  /v1/jobs/{id}                GetJob
+//	POST /v1/jobs/{id}:cancel         CancelJob
+//
+// An ExecuteAction started as a job answers 202 Accepted with the job's
+// URL in Location.
+//
+// Calls go through the same unary interceptors as gRPC, with the caller's
+// TLS or unix peer credentials and headers presented as gRPC peer and metadata.
+type Gateway struct {
+	Server      *SkillServer
+	Interceptor grpc.UnaryServerInterceptor // Usually ChainUnary of the gRPC server's interceptors
+}
+
+// Handler returns the gateway's HTTP handler.
+func (g *Gateway) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/actions", g.getActions)
+	mux.HandleFunc("POST /v1/actions/{action}", g.executeAction)
+	mux.HandleFunc("POST /v1/actions:batchExecute", g.batchExecuteActions)
+	mux.HandleFunc("GET /v1/jobs", g.listJobs)
+	mux.HandleFunc("GET /v1/jobs/{id}", g.getJob)
+	mux.HandleFunc("POST /v1/jobs/{id}", g.cancelJob)
+	return otelhttp.NewHandler(mux, "gateway", otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+		return r.Method + " " + r.Pattern
+	}))
+}
+
+func (g *Gateway) getActions(w http.ResponseWriter, r *http.Request) {
+	req := &pb.GetActionRequest{Task: r.URL.Query().Get("task")}
+	g.invoke(w, r, pb.SkillService_GetActions_FullMethodName, req, func(ctx context.Context, req any) (any, error) {
+		return g.Server.GetActions(ctx, req.(*pb.GetActionRequest))
+	})
+}
+
+func (g *Gateway) executeAction(w http.ResponseWriter, r *http.Request) {
+	name, ok := strings.CutSuffix(r.PathValue("action"), ":execute")
+	if !ok || name == "" {
+		writeStatus(w, status.New(codes.NotFound, "expected POST /v1/actions/{name}:execute"))
+		return
+	}
+
+	req := &pb.ExecuteActionRequest{}
+	if !readBody(w, r, req) {
+		return
+	}
+	req.Name = name
+
+	g.invoke(w, r, pb.SkillService_ExecuteAction_FullMethodName, req, func(ctx context.Context, req any) (any, error) {
+		return g.Server.ExecuteAction(ctx, req.(*pb.ExecuteActionRequest))
+	})
+}
+
+func (g *Gateway) batchExecuteActions(w http.ResponseWriter, r *http.Request) {
+	req := &pb.BatchExecuteActionsRequest{}
+	if !readBody(w, r, req) {
+		return
+	}
+	g.invoke(w, r, pb.SkillService_BatchExecuteActions_FullMethodName, req, func(ctx context.Context, req any) (any, error) {
+		return g.Server.BatchExecuteActions(ctx, req.(*pb.BatchExecuteActionsRequest))
+	})
+}
+
+func (g *Gateway) listJobs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := &pb.ListJobsRequest{Action: query.Get("action")}
+	if state := query.Get("state"); state != "" {
+		v, ok := pb.JobState_value[state]
+		if !ok {
+			writeStatus(w, status.Newf(codes.InvalidArgument, "unknown job state %q", state))
+			return
+		}
+		req.State = pb.JobState(v)
+	}
+	g.invoke(w, r, pb.SkillService_ListJobs_FullMethodName, req, func(ctx context.Context, req any) (any, error) {
+		return g.Server.ListJobs(ctx, req.(*pb.ListJobsRequest))
+	})
+}
+
+func (g *Gateway) getJob(w http.ResponseWriter, r *http.Request) {
+	req := &pb.GetJobRequest{Id: r.PathValue("id")}
+	g.invoke(w, r, pb.SkillService_GetJob_FullMethodName, req, func(ctx context.Context, req any) (any, error) {
+		return g.Server.GetJob(ctx, req.(*pb.GetJobRequest))
+	})
+}
+
+func (g *Gateway) cancelJob(w http.ResponseWriter, r *http.Request) {
+	id, ok := strings.CutSuffix(r.PathValue("id"), ":cancel")
+	if !ok || id == "" {
+		writeStatus(w, status.New(codes.NotFound, "expected POST /v1/jobs/{id}:cancel"))
+		return
+	}
+	req := &pb.CancelJobRequest{Id: id}
+	g.invoke(w, r, pb.SkillService_CancelJob_FullMethodName, req, func(ctx context.Context, req any) (any, error) {
+		return g.Server.CancelJob(ctx, req.(*pb.CancelJobRequest))
+	})
+}
+
+// readBody decodes an optional JSON request body into msg, writing the error
+// response and reporting false when it cannot.
+func readBody(w http.ResponseWriter, r *http.Request, msg proto.Message) bool {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxGatewayBody))
+	if err != nil {
+		writeStatus(w, status.New(codes.InvalidArgument, err.Error()))
+		return false
+	}
+	if len(body) > 0 {
+		if err := protojson.Unmarshal(body, msg); err != nil {
+			writeStatus(w, status.Newf(codes.InvalidArgument, "invalid request body: %v", err))
+			return false
+		}
+	}
+	return true
+}
+
+// invoke runs the handler behind the interceptors and writes the response.
+func (g *Gateway) invoke(w http.ResponseWriter, r *http.Request, method string, req any, handler grpc.UnaryHandler) {
+	ctx := gatewayContext(r)
+	stream := &gatewayStream{method: method, header: w.Header()}
+	ctx = grpc.NewContextWithServerTransportStream(ctx, stream)
+
+	info := &grpc.UnaryServerInfo{Server: g.Server, FullMethod: method}
+	var res any
+	var err error
+	if g.Interceptor != nil {
+		res, err = g.Interceptor(ctx, req, info, handler)
+	} else {
+		res, err = handler(ctx, req)
+	}
+	if err != nil {
+		writeStatus(w, status.Convert(err))
+		return
+	}
+	b, err := protojson.Marshal(res.(proto.Message))
+	if err != nil {
+		writeStatus(w, status.Convert(err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if started, ok := res.(*pb.ExecuteActionResponse); ok && started.GetJob() != nil {
+		w.Header().Set("Location", "/v1/jobs/"+started.GetJob().GetId())
+		w.WriteHeader(http.StatusAccepted)
+	}
+	w.Write(b)
+}
+
+// gatewayContext presents the HTTP caller as a gRPC peer with metadata.
+func gatewayContext(r *http.Request) context.Context {
+	ctx := r.Context()
+
+	md := metadata.MD{}
+	for _, h := range gatewayHeaders {
+		if v := r.Header.Values(h); len(v) > 0 {
+			md.Set(h, v...)
+		}
+	}
+	ctx = metadata.NewIncomingContext(ctx, md)
+
+	p := &peer.Peer{}
+	if conn, ok := ctx.Value(gatewayConnKey{}).(net.Conn); ok {
+		p.Addr = conn.RemoteAddr()
+		if uc, ok := conn.(*net.UnixConn); ok {
+			if info, err := peerCredentials(uc); err == nil {
+				p.AuthInfo = info
+			} else {
+				slog.WarnContext(ctx, "Could not read gateway peer credentials", "error", err)
+			}
+		}
+	}
+	if r.TLS != nil {
+		p.AuthInfo = credentials.TLSInfo{State: *r.TLS, CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.PrivacyAndIntegrity}}
+	}
+	return peer.NewContext(ctx, p)
+}
+
+type gatewayConnKey struct{}
+
+// GatewayConnContext is the http.Server ConnContext hook that lets the
+// gateway read unix peer credentials from the connection.
+func GatewayConnContext(ctx context.Context, c net.Conn) context.Context {
+	return context.WithValue(ctx, gatewayConnKey{}, c)
+}
+
+// ChainUnary combines interceptors into one, outermost first, as
+// grpc.ChainUnaryInterceptor does.
+func ChainUnary(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		next := handler
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			interceptor, inner := interceptors[i], next
+			next = func(ctx context.Context, req any) (any, error) {
+				return interceptor(ctx, req, info, inner)
+			}
+		}
+		return next(ctx, req)
+	}
+}
+
+// gatewayStream turns grpc.SetHeader calls into HTTP response headers.
+type gatewayStream struct {
+	method string
+	header http.Header
+}
+
+func (s *gatewayStream) Method() string {
+	return s.method
+}
+
+func (s *gatewayStream) SetHeader(md metadata.MD) error {
+	for k, v := range md {
+		for _, item := range v {
+			s.header.Add(k, item)
+		}
+	}
+	return nil
+}
+
+func (s *gatewayStream) SendHeader(md metadata.MD) error {
+	return s.SetHeader(md)
+}
+
+func (s *gatewayStream) SetTrailer(md metadata.MD) error {
+	return s.SetHeader(md)
+}
+
+// writeStatus writes a gRPC status as a google.rpc.Status JSON body.
+func writeStatus(w http.ResponseWriter, st *status.Status) {
+	b, _ := protojson.Marshal(st.Proto())
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus(st.Code()))
+	w.Write(b)
+}
+
+// httpStatus maps gRPC codes to HTTP statuses as the Google API gateways do.
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
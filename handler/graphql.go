@@ -0,0 +1,173 @@
+package skill
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ActionGraphQL sends a GraphQL operation to the action's base_url.
+const ActionGraphQL = "graphql"
+
+// GraphQLSpec is the graphql section of a graphql action:
+//
+//	graphql:
+//	  query: |
+//	    query Issues($owner: String!, $repo: String!, $first: Int) {
+//	      repository(owner: $owner, name: $repo) { issues(first: $first) { nodes { number title } } }
+//	    }
+//
+// Body params are sent as the operation's variables, and the response's
+// data is what templates see.
+type GraphQLSpec struct {
+	Query         string `yaml:"query"`                    // The query or mutation document
+	OperationName string `yaml:"operation_name,omitempty"` // Operation to run when the document holds several
+
+	operation ast.Operation // Kind of the operation, found at startup
+	variables []string      // Variables the operation declares
+}
+
+func (g *GraphQLSpec) validate(params []*Param) error {
+	if g == nil || strings.TrimSpace(g.Query) == "" {
+		return fmt.Errorf("graphql action needs graphql.query")
+	}
+	doc, err := parser.ParseQuery(&ast.Source{Name: "query", Input: g.Query})
+	if err != nil {
+		return fmt.Errorf("graphql query: %w", err)
+	}
+	var op *ast.OperationDefinition
+	switch {
+	case g.OperationName != "":
+		if op = doc.Operations.ForName(g.OperationName); op == nil {
+			return fmt.Errorf("graphql query has no operation '%s'", g.OperationName)
+		}
+	case len(doc.Operations) == 1:
+		op = doc.Operations[0]
+	default:
+		return fmt.Errorf("graphql query has %d operations, set operation_name", len(doc.Operations))
+	}
+	if op.Operation == ast.Subscription {
+		return fmt.Errorf("graphql subscriptions are not supported")
+	}
+
+	declared := make(map[string]string, len(params))
+	for _, p := range params {
+		declared[p.Name] = strings.ToLower(p.In)
+	}
+	g.variables = g.variables[:0]
+	for _, v := range op.VariableDefinitions {
+		in, ok := declared[v.Variable]
+		if !ok {
+			return fmt.Errorf("graphql variable $%s has no param", v.Variable)
+		}
+		// Only body params are sent, a variable bound to any other would
+		// silently go out empty
+		if in != "body" {
+			return fmt.Errorf("graphql variable $%s needs its param in body, not %q", v.Variable, in)
+		}
+		g.variables = append(g.variables, v.Variable)
+	}
+	g.operation = op.Operation
+	return nil
+}
+
+// isMutation reports whether the operation changes data, which is what
+// confirm: auto asks approval for.
+func (g *GraphQLSpec) isMutation() bool {
+	return g.operation == ast.Mutation
+}
+
+// payload is the GraphQL request body. Only the params the operation
+// declares are sent as variables.
+func (g *GraphQLSpec) payload(params map[string]any) ([]byte, error) {
+	variables := make(map[string]any, len(g.variables))
+	for _, name := range g.variables {
+		if v, ok := params[name]; ok {
+			variables[name] = v
+		}
+	}
+	return json.Marshal(struct {
+		Query         string         `json:"query"`
+		OperationName string         `json:"operationName,omitempty"`
+		Variables     map[string]any `json:"variables"`
+	}{g.Query, g.OperationName, variables})
+}
+
+// graphqlError is an entry of a response's errors. Servers put a machine
+// readable code in extensions.code (Apollo) or type (GitHub).
+type graphqlError struct {
+	Message    string `json:"message"`
+	Path       []any  `json:"path"`
+	Type       string `json:"type"`
+	Extensions struct {
+		Code string `json:"code"`
+	} `json:"extensions"`
+}
+
+// graphqlCodes maps common GraphQL error codes to gRPC codes.
+var graphqlCodes = map[string]codes.Code{
+	"UNAUTHENTICATED":           codes.Unauthenticated,
+	"FORBIDDEN":                 codes.PermissionDenied,
+	"NOT_FOUND":                 codes.NotFound,
+	"BAD_USER_INPUT":            codes.InvalidArgument,
+	"GRAPHQL_PARSE_FAILED":      codes.InvalidArgument,
+	"GRAPHQL_VALIDATION_FAILED": codes.InvalidArgument,
+	"ARGUMENT_ERROR":            codes.InvalidArgument,
+	"RATE_LIMITED":              codes.ResourceExhausted,
+	"THROTTLED":                 codes.ResourceExhausted,
+	"INTERNAL_SERVER_ERROR":     codes.Internal,
+}
+
+// graphqlResult turns a GraphQL response into the call's result: data on
+// success, and errors as a reported error whose code comes from the first
+// error. Partial data that comes with errors is kept for the templates.
+func graphqlResult(resp *http.Response, body []byte) ActionResult {
+	res := ActionResult{StatusCode: resp.StatusCode, Header: resp.Header}
+	var reply struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphqlError  `json:"errors"`
+	}
+	err := json.Unmarshal(body, &reply)
+	switch {
+	case err == nil && len(reply.Errors) > 0:
+		code := codes.Unknown
+		messages := make([]string, len(reply.Errors))
+		for i, e := range reply.Errors {
+			messages[i] = e.Message
+			if len(e.Path) > 0 {
+				messages[i] += fmt.Sprintf(" (at %s)", joinPath(e.Path))
+			}
+		}
+		first := reply.Errors[0]
+		if c, ok := graphqlCodes[strings.ToUpper(cmp.Or(first.Extensions.Code, first.Type))]; ok {
+			code = c
+		}
+		res.Error = status.Errorf(code, "GraphQL error: %s", strings.Join(messages, "; "))
+		res.Reported = true
+		if len(reply.Data) > 0 && string(reply.Data) != "null" {
+			res.Result = string(reply.Data)
+		}
+	case resp.StatusCode >= http.StatusBadRequest:
+		res.Error = fmt.Errorf("HTTP error: %s, body: %s", resp.Status, string(body))
+	case err != nil || len(reply.Data) == 0 || string(reply.Data) == "null":
+		res.Error = fmt.Errorf("GraphQL response has no data: %s", string(body))
+	default:
+		res.Result = string(reply.Data)
+	}
+	return res
+}
+
+func joinPath(path []any) string {
+	parts := make([]string, len(path))
+	for i, p := range path {
+		parts[i] = fmt.Sprint(p)
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,370 @@
+package skill
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// GraphQLImport selects what ImportGraphQL generates.
+type GraphQLImport struct {
+	Endpoint  string   // base_url of the generated actions
+	Fields    []string // Root fields to import, all when empty
+	Mutations bool     // Import mutation fields as well as query fields
+	Depth     int      // Levels of nested objects selected by the generated queries
+}
+
+// ImportGraphQL generates a graphql action for each root field of a schema,
+// given as an introspection result (JSON) or in the schema definition
+// language. The actions' params are the fields' arguments and their queries
+// select the scalar fields of the result down to opts.Depth levels.
+func ImportGraphQL(schema []byte, opts GraphQLImport) (map[string]*Action, error) {
+	var s *gqlSchema
+	var err error
+	if trimmed := bytes.TrimSpace(schema); len(trimmed) > 0 && trimmed[0] == '{' {
+		s, err = parseIntrospection(trimmed)
+	} else {
+		s, err = parseSDL(string(schema))
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.byName = make(map[string]*gqlType, len(s.Types))
+	for _, t := range s.Types {
+		s.byName[t.Name] = t
+	}
+	return s.actions(opts)
+}
+
+// gqlSchema mirrors the parts of an introspection result the import uses.
+// SDL schemas are converted to the same shape.
+type gqlSchema struct {
+	QueryType    *gqlTypeRef `json:"queryType"`
+	MutationType *gqlTypeRef `json:"mutationType"`
+	Types        []*gqlType  `json:"types"`
+
+	byName map[string]*gqlType
+}
+
+type gqlType struct {
+	Kind        string      `json:"kind"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Fields      []*gqlField `json:"fields"`
+	InputFields []*gqlInput `json:"inputFields"`
+	EnumValues  []struct {
+		Name string `json:"name"`
+	} `json:"enumValues"`
+}
+
+type gqlField struct {
+	Name         string      `json:"name"`
+	Description  string      `json:"description"`
+	Args         []*gqlInput `json:"args"`
+	Type         *gqlTypeRef `json:"type"`
+	IsDeprecated bool        `json:"isDeprecated"`
+}
+
+type gqlInput struct {
+	Name         string      `json:"name"`
+	Description  string      `json:"description"`
+	Type         *gqlTypeRef `json:"type"`
+	DefaultValue *string     `json:"defaultValue"`
+}
+
+func (in *gqlInput) required() bool {
+	return in.Type.Kind == "NON_NULL" && in.DefaultValue == nil
+}
+
+type gqlTypeRef struct {
+	Kind   string      `json:"kind"`
+	Name   string      `json:"name"`
+	OfType *gqlTypeRef `json:"ofType"`
+}
+
+// named returns the name of the type under any list and non-null wrappers.
+func (r *gqlTypeRef) named() string {
+	for r.OfType != nil {
+		r = r.OfType
+	}
+	return r.Name
+}
+
+// String is the reference as written in a variable definition, e.g. [ID!]!.
+func (r *gqlTypeRef) String() string {
+	switch r.Kind {
+	case "NON_NULL":
+		return r.OfType.String() + "!"
+	case "LIST":
+		return "[" + r.OfType.String() + "]"
+	default:
+		return r.Name
+	}
+}
+
+func parseIntrospection(data []byte) (*gqlSchema, error) {
+	var result struct {
+		Data struct {
+			Schema *gqlSchema `json:"__schema"`
+		} `json:"data"`
+		Schema *gqlSchema `json:"__schema"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("invalid introspection result: %w", err)
+	}
+	if result.Data.Schema != nil {
+		return result.Data.Schema, nil
+	}
+	if result.Schema != nil {
+		return result.Schema, nil
+	}
+	return nil, fmt.Errorf("introspection result has no __schema")
+}
+
+func parseSDL(sdl string) (*gqlSchema, error) {
+	schema, err := gqlparser.LoadSchema(&ast.Source{Name: "schema", Input: sdl})
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	s := &gqlSchema{}
+	if schema.Query != nil {
+		s.QueryType = &gqlTypeRef{Name: schema.Query.Name}
+	}
+	if schema.Mutation != nil {
+		s.MutationType = &gqlTypeRef{Name: schema.Mutation.Name}
+	}
+	for name, def := range schema.Types {
+		t := &gqlType{Kind: string(def.Kind), Name: name, Description: def.Description}
+		for _, f := range def.Fields {
+			if strings.HasPrefix(f.Name, "__") {
+				continue
+			}
+			if def.Kind == ast.InputObject {
+				t.InputFields = append(t.InputFields, sdlInput(f.Name, f.Description, f.Type, f.DefaultValue))
+				continue
+			}
+			field := &gqlField{
+				Name:         f.Name,
+				Description:  f.Description,
+				Type:         sdlRef(f.Type),
+				IsDeprecated: f.Directives.ForName("deprecated") != nil,
+			}
+			for _, arg := range f.Arguments {
+				field.Args = append(field.Args, sdlInput(arg.Name, arg.Description, arg.Type, arg.DefaultValue))
+			}
+			t.Fields = append(t.Fields, field)
+		}
+		for _, v := range def.EnumValues {
+			t.EnumValues = append(t.EnumValues, struct {
+				Name string `json:"name"`
+			}{v.Name})
+		}
+		s.Types = append(s.Types, t)
+	}
+	return s, nil
+}
+
+func sdlInput(name, desc string, typ *ast.Type, def *ast.Value) *gqlInput {
+	in := &gqlInput{Name: name, Description: desc, Type: sdlRef(typ)}
+	if def != nil {
+		v := def.String()
+		in.DefaultValue = &v
+	}
+	return in
+}
+
+func sdlRef(t *ast.Type) *gqlTypeRef {
+	if t.NonNull {
+		inner := *t
+		inner.NonNull = false
+		return &gqlTypeRef{Kind: "NON_NULL", OfType: sdlRef(&inner)}
+	}
+	if t.Elem != nil {
+		return &gqlTypeRef{Kind: "LIST", OfType: sdlRef(t.Elem)}
+	}
+	return &gqlTypeRef{Name: t.NamedType}
+}
+
+func (s *gqlSchema) actions(opts GraphQLImport) (map[string]*Action, error) {
+	type root struct {
+		operation string
+		ref       *gqlTypeRef
+	}
+	roots := []root{{"query", s.QueryType}}
+	if opts.Mutations {
+		roots = append(roots, root{"mutation", s.MutationType})
+	}
+
+	actions := make(map[string]*Action)
+	found := make(map[string]bool)
+	for _, r := range roots {
+		if r.ref == nil || s.byName[r.ref.Name] == nil {
+			continue
+		}
+		for _, f := range s.byName[r.ref.Name].Fields {
+			if len(opts.Fields) > 0 && !slices.Contains(opts.Fields, f.Name) {
+				continue
+			}
+			found[f.Name] = true
+			name := strings.ToUpper(f.Name[:1]) + f.Name[1:]
+			if _, ok := actions[name]; ok {
+				name = strings.ToUpper(r.operation[:1]) + r.operation[1:] + name
+			}
+			action, err := s.action(r.operation, name, f, opts)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", r.operation, f.Name, err)
+			}
+			actions[name] = action
+		}
+	}
+	for _, f := range opts.Fields {
+		if !found[f] {
+			return nil, fmt.Errorf("schema has no root field '%s'", f)
+		}
+	}
+	return actions, nil
+}
+
+// action generates the action calling root field f.
+func (s *gqlSchema) action(operation, name string, f *gqlField, opts GraphQLImport) (*Action, error) {
+	var params []*Param
+	var variables, arguments []string
+	for _, arg := range f.Args {
+		p := s.param(arg.Name, arg.Description, arg.Type, 0)
+		p.In = "body"
+		p.Required = arg.required()
+		params = append(params, p)
+		variables = append(variables, fmt.Sprintf("$%s: %s", arg.Name, arg.Type))
+		arguments = append(arguments, fmt.Sprintf("%s: $%s", arg.Name, arg.Name))
+	}
+
+	var query strings.Builder
+	query.WriteString(operation + " " + name)
+	if len(variables) > 0 {
+		query.WriteString("(" + strings.Join(variables, ", ") + ")")
+	}
+	query.WriteString(" {\n  " + f.Name)
+	if len(arguments) > 0 {
+		query.WriteString("(" + strings.Join(arguments, ", ") + ")")
+	}
+	query.WriteString(s.selection(f.Type.named(), max(opts.Depth, 1), "  ", map[string]bool{}))
+	query.WriteString("\n}\n")
+
+	desc := f.Description
+	if desc == "" {
+		desc = fmt.Sprintf("GraphQL %s %s", operation, f.Name)
+	}
+	action := &Action{
+		Desc:    desc,
+		Type:    ActionGraphQL,
+		BaseURL: opts.Endpoint,
+		Params:  params,
+		GraphQL: &GraphQLSpec{Query: query.String()},
+		ResponseTemplate: ResponseTemplate{
+			Success: fmt.Sprintf("{{ .%s }}", f.Name),
+			Failure: name + " failed: {{ .Error }}",
+		},
+	}
+	if err := action.GraphQL.validate(params); err != nil {
+		return nil, err
+	}
+	return action, nil
+}
+
+// maxInputDepth bounds the properties generated for recursive input types.
+const maxInputDepth = 5
+
+// param describes an argument or input field of type ref.
+func (s *gqlSchema) param(name, desc string, ref *gqlTypeRef, depth int) *Param {
+	p := &Param{Name: name, Desc: desc}
+	if ref.Kind == "NON_NULL" {
+		ref = ref.OfType
+	}
+	if ref.Kind == "LIST" {
+		p.Type = "array"
+		item := s.param("", "", ref.OfType, depth)
+		if item.Type == "object" {
+			p.Items = item.Properties
+		} else {
+			p.Items = []*Param{{Type: item.Type, Enum: item.Enum}}
+		}
+		return p
+	}
+
+	t := s.byName[ref.Name]
+	switch {
+	case t == nil:
+		p.Type = "string"
+	case t.Kind == "ENUM":
+		p.Type = "string"
+		for _, v := range t.EnumValues {
+			p.Enum = append(p.Enum, v.Name)
+		}
+	case t.Kind == "INPUT_OBJECT":
+		p.Type = "object"
+		if depth >= maxInputDepth {
+			break
+		}
+		for _, in := range t.InputFields {
+			prop := s.param(in.Name, in.Description, in.Type, depth+1)
+			prop.Required = in.required()
+			p.Properties = append(p.Properties, prop)
+		}
+	default:
+		p.Type = scalarType(ref.Name)
+	}
+	return p
+}
+
+func scalarType(name string) string {
+	switch name {
+	case "Int":
+		return "integer"
+	case "Float":
+		return "number"
+	case "Boolean":
+		return "boolean"
+	default:
+		return "string"
+	}
+}
+
+// selection is the selection set for a field of the named type: its scalar
+// fields, and its object fields while depth lasts. Fields needing arguments
+// and deprecated fields are left out.
+func (s *gqlSchema) selection(typeName string, depth int, indent string, seen map[string]bool) string {
+	t := s.byName[typeName]
+	if t == nil {
+		return ""
+	}
+	var lines []string
+	switch t.Kind {
+	case "OBJECT", "INTERFACE":
+		seen[typeName] = true
+		defer delete(seen, typeName)
+		for _, f := range t.Fields {
+			if f.IsDeprecated || slices.ContainsFunc(f.Args, (*gqlInput).required) {
+				continue
+			}
+			ft := s.byName[f.Type.named()]
+			switch {
+			case ft == nil || ft.Kind == "SCALAR" || ft.Kind == "ENUM":
+				lines = append(lines, indent+"  "+f.Name)
+			case depth > 1 && !seen[ft.Name]:
+				lines = append(lines, indent+"  "+f.Name+s.selection(ft.Name, depth-1, indent+"  ", seen))
+			}
+		}
+	case "UNION":
+	default:
+		return ""
+	}
+	if len(lines) == 0 {
+		lines = []string{indent + "  __typename"}
+	}
+	return " {\n" + strings.Join(lines, "\n") + "\n" + indent + "}"
+}
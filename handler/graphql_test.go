@@ -0,0 +1,73 @@
+package skill
+
+import (
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGraphQLResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		result   string
+		code     codes.Code
+		reported bool
+	}{
+		{
+			name:   "data",
+			status: http.StatusOK,
+			body:   `{"data": {"viewer": {"login": "octo"}}}`,
+			result: `{"viewer": {"login": "octo"}}`,
+		},
+		{
+			name:     "errors without data",
+			status:   http.StatusOK,
+			body:     `{"data": null, "errors": [{"message": "no such repo", "type": "NOT_FOUND"}]}`,
+			code:     codes.NotFound,
+			reported: true,
+		},
+		{
+			name:     "partial data",
+			status:   http.StatusOK,
+			body:     `{"data": {"repo": {"stars": null}}, "errors": [{"message": "hidden", "extensions": {"code": "FORBIDDEN"}, "path": ["repo", "stars"]}]}`,
+			result:   `{"repo": {"stars": null}}`,
+			code:     codes.PermissionDenied,
+			reported: true,
+		},
+		{
+			name:     "errors on a bad request",
+			status:   http.StatusBadRequest,
+			body:     `{"errors": [{"message": "Cannot query field", "extensions": {"code": "GRAPHQL_VALIDATION_FAILED"}}]}`,
+			code:     codes.InvalidArgument,
+			reported: true,
+		},
+		{
+			name:   "server error",
+			status: http.StatusInternalServerError,
+			body:   `{"message": "boom"}`,
+			code:   codes.Unknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := graphqlResult(&http.Response{StatusCode: tt.status, Header: http.Header{}}, []byte(tt.body))
+			if tt.code == codes.OK {
+				if res.Error != nil {
+					t.Fatalf("error = %v, want none", res.Error)
+				}
+			} else if got := status.Code(res.Error); res.Error == nil || got != tt.code {
+				t.Fatalf("error = %v, want code %s", res.Error, tt.code)
+			}
+			if res.Result != tt.result {
+				t.Errorf("result = %q, want %q", res.Result, tt.result)
+			}
+			if res.Reported != tt.reported {
+				t.Errorf("reported = %v, want %v", res.Reported, tt.reported)
+			}
+		})
+	}
+}
@@ -0,0 +1,363 @@
+package skill
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ActionGRPC calls a unary method of an upstream gRPC service.
+const ActionGRPC = "grpc"
+
+// GRPCSpec is the grpc section of a grpc action:
+//
+//	grpc:
+//	  target: orders.internal:443
+//	  method: orders.v1.OrderService/GetOrder
+//	  descriptor_set: protos/orders.pb
+//
+// The request message is built from the call's params as protobuf JSON, and
+// templates see the response message as JSON with the proto field names.
+// Without descriptor_set the method is looked up with server reflection on
+// the first call. A plaintext connection carries no credentials unless
+// allow_plaintext_credentials is set.
+type GRPCSpec struct {
+	Target                    string `yaml:"target"`                      // host:port of the upstream
+	Method                    string `yaml:"method"`                      // Full method name, e.g. orders.v1.OrderService/GetOrder
+	DescriptorSet             string `yaml:"descriptor_set"`              // FileDescriptorSet written by protoc --include_imports --descriptor_set_out
+	Plaintext                 bool   `yaml:"plaintext"`                   // Connect without TLS
+	AllowPlaintextCredentials bool   `yaml:"allow_plaintext_credentials"` // Send the bearer token and authorization header over plaintext too
+	ServerName                string `yaml:"server_name"`                 // TLS server name, defaults to the target's host
+
+	service string // Full name of the service
+	name    string // Method name within the service
+
+	mu        sync.Mutex
+	conn      *grpc.ClientConn
+	method    protoreflect.MethodDescriptor
+	resolving singleflight.Group // Reflection of the method, one at a time
+}
+
+// cleartextCredentials reports whether credentials would cross the network
+// unencrypted, which needs allow_plaintext_credentials.
+func (g *GRPCSpec) cleartextCredentials() bool {
+	return g.Plaintext && !g.AllowPlaintextCredentials
+}
+
+func (g *GRPCSpec) validate(headers map[string]string) error {
+	if g == nil || g.Target == "" || g.Method == "" {
+		return fmt.Errorf("grpc action needs grpc.target and grpc.method")
+	}
+	if _, _, err := net.SplitHostPort(g.Target); err != nil {
+		return fmt.Errorf("grpc target must be host:port: %w", err)
+	}
+	method := strings.TrimPrefix(g.Method, "/")
+	i := strings.LastIndexAny(method, "/.")
+	if i <= 0 || i == len(method)-1 {
+		return fmt.Errorf("grpc method %q must be package.Service/Method", g.Method)
+	}
+	g.service, g.name = method[:i], method[i+1:]
+	if g.cleartextCredentials() {
+		for key := range headers {
+			if strings.EqualFold(key, "authorization") {
+				return fmt.Errorf("grpc plaintext would send the authorization header unencrypted, set allow_plaintext_credentials to allow it")
+			}
+		}
+	}
+
+	if g.DescriptorSet == "" {
+		return nil
+	}
+	data, err := os.ReadFile(g.DescriptorSet)
+	if err != nil {
+		return fmt.Errorf("reading descriptor_set: %w", err)
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("descriptor_set %s: %w", g.DescriptorSet, err)
+	}
+	g.method, err = findMethod(&set, g.service, g.name)
+	return err
+}
+
+// findMethod looks the method up in the files of set, which must include
+// their imports.
+func findMethod(set *descriptorpb.FileDescriptorSet, service, name string) (protoreflect.MethodDescriptor, error) {
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("loading descriptors: %w", err)
+	}
+	desc, err := files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("service %s: %w", service, err)
+	}
+	svc, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", service)
+	}
+	method := svc.Methods().ByName(protoreflect.Name(name))
+	if method == nil {
+		return nil, fmt.Errorf("service %s has no method %s", service, name)
+	}
+	if method.IsStreamingClient() || method.IsStreamingServer() {
+		return nil, fmt.Errorf("method %s/%s is streaming, only unary methods are supported", service, name)
+	}
+	return method, nil
+}
+
+// fullMethod is the method's path on the wire.
+func (g *GRPCSpec) fullMethod() string {
+	return "/" + g.service + "/" + g.name
+}
+
+// client returns the connection to the upstream, dialing it on first use.
+// Connections go through the guard's host and address checks and trust the
+// CAs and client certificate of the manifest's http section.
+func (g *GRPCSpec) client(guard *NetGuard, transport *http.Transport) (*grpc.ClientConn, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.conn != nil {
+		return g.conn, nil
+	}
+
+	host, _, _ := net.SplitHostPort(g.Target)
+	if err := guard.CheckHost(host); err != nil {
+		return nil, fmt.Errorf("request blocked: %w", err)
+	}
+	creds := insecure.NewCredentials()
+	if !g.Plaintext {
+		tlsConfig := transport.TLSClientConfig.Clone()
+		tlsConfig.ServerName = g.ServerName
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	dialer := &net.Dialer{Control: guard.Control}
+	conn, err := grpc.NewClient(g.Target,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "tcp", addr)
+		}),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	g.conn = conn
+	return conn, nil
+}
+
+// resolve returns the method's descriptor, asking the upstream's reflection
+// service when no descriptor set was given. Calls arriving while it is asked
+// share the answer instead of holding up other users of the spec, and a
+// failure is not kept so the next call asks again.
+func (g *GRPCSpec) resolve(ctx context.Context, conn *grpc.ClientConn) (protoreflect.MethodDescriptor, error) {
+	g.mu.Lock()
+	method := g.method
+	g.mu.Unlock()
+	if method != nil {
+		return method, nil
+	}
+
+	ch := g.resolving.DoChan(g.fullMethod(), func() (any, error) {
+		set, err := reflectFiles(ctx, conn, g.service)
+		if err != nil {
+			return nil, fmt.Errorf("server reflection: %w", err)
+		}
+		method, err := findMethod(set, g.service, g.name)
+		if err != nil {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		g.mu.Lock()
+		g.method = method
+		g.mu.Unlock()
+		return method, nil
+	})
+	select {
+	case r := <-ch:
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		return r.Val.(protoreflect.MethodDescriptor), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// reflectFiles fetches the file defining symbol and everything it imports.
+func reflectFiles(ctx context.Context, conn *grpc.ClientConn, symbol string) (*descriptorpb.FileDescriptorSet, error) {
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.CloseSend()
+
+	files := make(map[string]*descriptorpb.FileDescriptorProto)
+	ask := func(req *rpb.ServerReflectionRequest) error {
+		if err := stream.Send(req); err != nil {
+			return err
+		}
+		res, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if e := res.GetErrorResponse(); e != nil {
+			return status.Error(codes.Code(e.ErrorCode), e.ErrorMessage)
+		}
+		for _, raw := range res.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			fd := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(raw, fd); err != nil {
+				return err
+			}
+			files[fd.GetName()] = fd
+		}
+		return nil
+	}
+
+	err = ask(&rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Servers may leave out imports they already sent on the stream, ask until none are missing
+	for {
+		var missing []string
+		for _, fd := range files {
+			for _, dep := range fd.GetDependency() {
+				if _, ok := files[dep]; !ok {
+					missing = append(missing, dep)
+				}
+			}
+		}
+		if len(missing) == 0 {
+			break
+		}
+		for _, name := range missing {
+			if _, ok := files[name]; ok {
+				continue
+			}
+			err := ask(&rpb.ServerReflectionRequest{
+				MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: name},
+			})
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := files[name]; !ok {
+				return nil, fmt.Errorf("server did not return %s", name)
+			}
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, fd := range files {
+		set.File = append(set.File, fd)
+	}
+	return set, nil
+}
+
+// metadata is the call's outgoing metadata: the action's headers with
+// secret:// references resolved, and the bearer token unless a header sets
+// authorization. Credentials for a plaintext connection are refused unless
+// the spec allows them.
+func (a *RunningAction) metadata(ctx context.Context) (metadata.MD, error) {
+	md := metadata.MD{}
+	for key, value := range a.Headers {
+		value, err := a.Secrets.Expand(ctx, value)
+		if err != nil {
+			return nil, err
+		}
+		md.Set(key, value)
+	}
+	if len(md.Get("authorization")) == 0 {
+		token, err := a.authToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			md.Set("authorization", "Bearer "+token)
+		}
+	}
+	if len(md.Get("authorization")) > 0 && a.GRPC.cleartextCredentials() {
+		return nil, status.Errorf(codes.FailedPrecondition, "refusing to send credentials to %s without TLS, set grpc.allow_plaintext_credentials to allow it", a.GRPC.Target)
+	}
+	return md, nil
+}
+
+// callGRPC is Execute for grpc actions.
+func (a *RunningAction) callGRPC(ctx context.Context, resultChan chan<- ActionResult) {
+	spec := a.GRPC
+	ctx, cancel := context.WithTimeout(ctx, a.Timeouts.Total)
+	defer cancel()
+
+	conn, err := spec.client(a.Guard, a.Transport)
+	if err != nil {
+		resultChan <- ActionResult{Error: err}
+		return
+	}
+	md, err := a.metadata(ctx)
+	if err != nil {
+		resultChan <- ActionResult{Error: err}
+		return
+	}
+	// Reflection is asked with the call's credentials too
+	ctx = metadata.NewOutgoingContext(ctx, md)
+	method, err := spec.resolve(ctx, conn)
+	if err != nil {
+		resultChan <- ActionResult{Error: err}
+		return
+	}
+
+	args, err := json.Marshal(a.arguments())
+	if err != nil {
+		resultChan <- ActionResult{Error: err}
+		return
+	}
+	req := dynamicpb.NewMessage(method.Input())
+	if err := protojson.Unmarshal(args, req); err != nil {
+		resultChan <- ActionResult{Error: status.Errorf(codes.InvalidArgument, "params do not match %s: %v", method.Input().FullName(), err)}
+		return
+	}
+
+	slog.DebugContext(ctx, "Calling gRPC method", "action", a.Name, "target", spec.Target, "method", spec.fullMethod())
+	res := dynamicpb.NewMessage(method.Output())
+	var header metadata.MD
+	err = conn.Invoke(ctx, spec.fullMethod(), req, res, grpc.Header(&header))
+	// Response metadata is kept as headers so rate limit hints are observed
+	h := http.Header{}
+	for key, values := range header {
+		for _, v := range values {
+			h.Add(key, v)
+		}
+	}
+	if err != nil {
+		resultChan <- ActionResult{Error: err, Header: h}
+		return
+	}
+	out, err := protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}.Marshal(res)
+	if err != nil {
+		resultChan <- ActionResult{Error: err}
+		return
+	}
+	resultChan <- ActionResult{Result: string(out), Header: h}
+}
@@ -0,0 +1,174 @@
+package skill
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	pb "yafai-skill/proto"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Defaults for the readiness probe.
+const (
+	DefaultProbeInterval = 30 * time.Second
+	DefaultProbeTimeout  = 5 * time.Second
+)
+
+// ReadinessProbe is the manifest's readiness section. It names either a
+// lightweight action to execute or a URL to GET with the skill's credentials.
+type ReadinessProbe struct {
+	Action           string            `yaml:"action"`
+	PathParams       map[string]any    `yaml:"path_params"`
+	QueryParams      map[string]any    `yaml:"query_params"`
+	BodyParams       map[string]any    `yaml:"body_params"`
+	URL              string            `yaml:"url"`
+	Headers          map[string]string `yaml:"headers"`           // Sent with the URL probe; secret:// references allowed
+	Interval         string            `yaml:"interval"`          // Time between probes, defaults to 30s
+	Timeout          string            `yaml:"timeout"`           // Defaults to 5s
+	FailureThreshold int               `yaml:"failure_threshold"` // Consecutive failures before NOT_SERVING, defaults to 1
+}
+
+// Readiness reports the skill's serving status through the grpc.health.v1
+// service, under the overall "" name, the SkillService name and the skill name.
+type Readiness struct {
+	Health *health.Server
+	Skill  string
+
+	server    *SkillServer
+	probe     *ReadinessProbe
+	interval  time.Duration
+	timeout   time.Duration
+	threshold int
+}
+
+// NewReadiness validates the probe and sets the initial status. Without a
+// probe the skill is always SERVING; with one it is NOT_SERVING until the
+// first probe passes.
+func NewReadiness(hs *health.Server, s *SkillServer, probe *ReadinessProbe) (*Readiness, error) {
+	r := &Readiness{Health: hs, Skill: s.Name, server: s, probe: probe}
+	if probe == nil {
+		r.set(healthpb.HealthCheckResponse_SERVING)
+		return r, nil
+	}
+
+	if (probe.Action == "") == (probe.URL == "") {
+		return nil, fmt.Errorf("readiness probe needs exactly one of action or url")
+	}
+	if probe.Action != "" {
+		def, ok := s.ActionsMap[probe.Action]
+		if !ok {
+			return nil, fmt.Errorf("readiness probe action '%s' not found", probe.Action)
+		}
+		// Probes run unattended and skip approvals, so they may only read
+		switch {
+		case def.Type == ActionWorkflow:
+			return nil, fmt.Errorf("readiness probe action '%s' is a workflow", probe.Action)
+		case !strings.EqualFold(def.Method, http.MethodGet):
+			return nil, fmt.Errorf("readiness probe action '%s' must be a GET", probe.Action)
+		case s.requiresApproval(def):
+			return nil, fmt.Errorf("readiness probe action '%s' requires approval", probe.Action)
+		}
+	}
+	var err error
+	if r.interval, err = parseDuration("readiness interval", probe.Interval, DefaultProbeInterval); err != nil {
+		return nil, err
+	}
+	if r.timeout, err = parseDuration("readiness timeout", probe.Timeout, DefaultProbeTimeout); err != nil {
+		return nil, err
+	}
+	r.threshold = max(probe.FailureThreshold, 1)
+
+	r.set(healthpb.HealthCheckResponse_NOT_SERVING)
+	return r, nil
+}
+
+func (r *Readiness) set(status healthpb.HealthCheckResponse_ServingStatus) {
+	for _, name := range []string{"", pb.SkillService_ServiceDesc.ServiceName, r.Skill} {
+		r.Health.SetServingStatus(name, status)
+	}
+}
+
+// Run probes the upstream until ctx is done. It returns at once without a probe.
+func (r *Readiness) Run(ctx context.Context) {
+	if r.probe == nil {
+		return
+	}
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	failures := 0
+	serving := false
+	for {
+		err := r.check(ctx)
+		switch {
+		case err == nil:
+			failures = 0
+			if !serving {
+				slog.Info("Readiness probe passed, serving", "skill", r.Skill)
+				serving = true
+				r.set(healthpb.HealthCheckResponse_SERVING)
+			}
+		case ctx.Err() != nil:
+			return
+		default:
+			failures++
+			slog.Warn("Readiness probe failed", "skill", r.Skill, "failures", failures, "error", err)
+			if serving && failures >= r.threshold {
+				serving = false
+				r.set(healthpb.HealthCheckResponse_NOT_SERVING)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check runs one probe. Probes skip approvals, the cache, rate limits and
+// circuit breakers so they always reach the upstream.
+func (r *Readiness) check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	a, err := r.probeAction()
+	if err != nil {
+		return err
+	}
+	resultChan := make(chan ActionResult, 1)
+	a.Execute(ctx, resultChan)
+	res := <-resultChan
+	return res.Error
+}
+
+func (r *Readiness) probeAction() (*RunningAction, error) {
+	p := r.probe
+	if p.Action != "" {
+		req := &pb.ExecuteActionRequest{Name: p.Action}
+		var err error
+		if req.PathParams, err = structpb.NewStruct(p.PathParams); err != nil {
+			return nil, err
+		}
+		if req.QueryParams, err = structpb.NewStruct(p.QueryParams); err != nil {
+			return nil, err
+		}
+		if req.BodyParams, err = structpb.NewStruct(p.BodyParams); err != nil {
+			return nil, err
+		}
+		return r.server.newRunningAction(req, r.server.ActionsMap[p.Action])
+	}
+	return r.server.newRunningAction(&pb.ExecuteActionRequest{Name: "readiness"}, &Action{
+		Method:  http.MethodGet,
+		BaseURL: p.URL,
+		Headers: p.Headers,
+	})
+}
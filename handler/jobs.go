@@ -0,0 +1,512 @@
+package skill
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "yafai-skill/proto"
+	"yafai-skill/secrets"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Defaults for background jobs.
+const (
+	DefaultJobRetention    = 24 * time.Hour
+	DefaultCallbackTimeout = 10 * time.Second
+)
+
+// JobsConfig is the manifest's jobs section.
+type JobsConfig struct {
+	Dir             string            `yaml:"dir"`              // Where job state is kept across restarts, defaults to ~/.yafai/jobs/<skill>
+	Retention       string            `yaml:"retention"`        // How long finished jobs are kept, defaults to 24h
+	CallbackURL     string            `yaml:"callback_url"`     // Webhook POSTed each job as JSON when it finishes
+	CallbackHeaders map[string]string `yaml:"callback_headers"` // Sent with the webhook; secret:// references allowed
+	Key             string            `yaml:"key"`              // Base64 32 byte key encrypting persisted jobs, defaults to cache_store.key
+}
+
+// Job is the persisted state of a background call.
+type Job struct {
+	ID         string      `json:"id"`
+	Action     string      `json:"action"`
+	State      pb.JobState `json:"state"`
+	Response   string      `json:"response,omitempty"`
+	ErrorCode  codes.Code  `json:"error_code,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	Caller     string      `json:"caller"`
+	RequestID  string      `json:"request_id,omitempty"`
+	PollURL    string      `json:"poll_url,omitempty"` // Upstream status URL, to resume polling after a restart
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+	FinishedAt time.Time   `json:"finished_at,omitzero"`
+}
+
+func (j *Job) finished() bool {
+	return j.State != pb.JobState_JOB_RUNNING
+}
+
+func (j *Job) toPB() *pb.Job {
+	job := &pb.Job{
+		Id:        j.ID,
+		Action:    j.Action,
+		State:     j.State,
+		Response:  j.Response,
+		Caller:    j.Caller,
+		RequestId: j.RequestID,
+		Polling:   j.PollURL != "" && !j.finished(),
+		CreatedAt: timestamppb.New(j.CreatedAt),
+		UpdatedAt: timestamppb.New(j.UpdatedAt),
+	}
+	if j.Error != "" {
+		job.Error = &pb.Error{Code: pb.ErrorCode(j.ErrorCode), Message: j.Error}
+	}
+	if !j.FinishedAt.IsZero() {
+		job.FinishedAt = timestamppb.New(j.FinishedAt)
+	}
+	return job
+}
+
+// JobStore keeps background jobs in memory and mirrors each to a file. With
+// a key the files are encrypted with AES-256-GCM; without one they are JSON
+// and leave out the response, which may hold upstream data.
+// A nil *JobStore has no jobs.
+type JobStore struct {
+	Config    JobsConfig
+	retention time.Duration
+	gcm       cipher.AEAD
+
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	cancels map[string]context.CancelFunc
+}
+
+// NewJobStore loads the jobs persisted in cfg.Dir. Jobs that were running
+// when the skill stopped are failed, except those polling an upstream, which
+// ResumeJobs picks up again.
+func NewJobStore(ctx context.Context, cfg JobsConfig, resolver *secrets.Resolver) (*JobStore, error) {
+	retention, err := parseDuration("retention", cfg.Retention, DefaultJobRetention)
+	if err != nil {
+		return nil, err
+	}
+	js := &JobStore{
+		Config:    cfg,
+		retention: retention,
+		jobs:      make(map[string]*Job),
+		cancels:   make(map[string]context.CancelFunc),
+	}
+	if cfg.Dir != "" && cfg.Key != "" {
+		if js.gcm, err = storeCipher(ctx, "jobs.key", cfg.Key, resolver); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Dir != "" {
+		if err := os.MkdirAll(cfg.Dir, 0700); err != nil {
+			return nil, fmt.Errorf("creating jobs directory: %w", err)
+		}
+		js.load()
+	}
+	return js, nil
+}
+
+func (js *JobStore) path(id string) string {
+	if js.gcm != nil {
+		return filepath.Join(js.Config.Dir, id+".enc")
+	}
+	return filepath.Join(js.Config.Dir, id+".json")
+}
+
+func (js *JobStore) persistLocked(j *Job) {
+	if js.Config.Dir == "" {
+		return
+	}
+	saved := *j
+	if js.gcm == nil {
+		saved.Response = ""
+	}
+	b, err := json.Marshal(&saved)
+	if err == nil && js.gcm != nil {
+		b, err = seal(js.gcm, b)
+	}
+	if err == nil {
+		err = os.WriteFile(js.path(j.ID), b, 0600)
+	}
+	if err != nil {
+		slog.Warn("Could not persist job", "job", j.ID, "error", err)
+	}
+}
+
+// load reads the persisted jobs. JSON files, which earlier versions wrote
+// with the response, are rewritten the way the store now keeps them, and
+// encrypted files the store has no key for are removed.
+func (js *JobStore) load() {
+	plain, err := filepath.Glob(filepath.Join(js.Config.Dir, "*.json"))
+	if err != nil {
+		return
+	}
+	encrypted, err := filepath.Glob(filepath.Join(js.Config.Dir, "*.enc"))
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	for _, f := range append(plain, encrypted...) {
+		b, err := os.ReadFile(f)
+		sealed := strings.HasSuffix(f, ".enc")
+		if err == nil && sealed {
+			if js.gcm == nil {
+				err = errors.New("no key to decrypt the job")
+			} else {
+				b, err = unseal(js.gcm, b)
+			}
+		}
+		var j Job
+		if err == nil {
+			err = json.Unmarshal(b, &j)
+		}
+		if err != nil || (j.finished() && now.Sub(j.FinishedAt) > js.retention) {
+			os.Remove(f)
+			continue
+		}
+		if !sealed {
+			os.Remove(f)
+			js.persistLocked(&j)
+		}
+		if !j.finished() && j.PollURL == "" {
+			// The call itself was in flight; re-sending it could repeat its effects
+			j.State = pb.JobState_JOB_FAILED
+			j.ErrorCode = codes.Aborted
+			j.Error = "interrupted by a restart of the skill"
+			j.UpdatedAt, j.FinishedAt = now, now
+			js.persistLocked(&j)
+		}
+		js.jobs[j.ID] = &j
+	}
+}
+
+// pruneLocked drops finished jobs past the retention period.
+func (js *JobStore) pruneLocked(now time.Time) {
+	for id, j := range js.jobs {
+		if j.finished() && now.Sub(j.FinishedAt) > js.retention {
+			delete(js.jobs, id)
+			if js.Config.Dir != "" {
+				os.Remove(js.path(id))
+			}
+		}
+	}
+}
+
+func (js *JobStore) add(j *Job, cancel context.CancelFunc) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	js.pruneLocked(time.Now())
+	js.jobs[j.ID] = j
+	js.cancels[j.ID] = cancel
+	js.persistLocked(j)
+}
+
+// Get returns a copy of the job.
+func (js *JobStore) Get(id string) (*Job, bool) {
+	if js == nil {
+		return nil, false
+	}
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	j, ok := js.jobs[id]
+	if !ok {
+		return nil, false
+	}
+	c := *j
+	return &c, true
+}
+
+// List returns copies of the jobs matching the filters, oldest first.
+func (js *JobStore) List(action string, state pb.JobState) []*Job {
+	if js == nil {
+		return nil
+	}
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	js.pruneLocked(time.Now())
+
+	var jobs []*Job
+	for _, j := range js.jobs {
+		if (action == "" || j.Action == action) && (state == pb.JobState_JOB_STATE_UNSPECIFIED || j.State == state) {
+			c := *j
+			jobs = append(jobs, &c)
+		}
+	}
+	sort.Slice(jobs, func(i, k int) bool { return jobs[i].CreatedAt.Before(jobs[k].CreatedAt) })
+	return jobs
+}
+
+// update applies fn to a running job and returns a copy, or reports false
+// once the job has finished.
+func (js *JobStore) update(id string, fn func(*Job)) (*Job, bool) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+	j, ok := js.jobs[id]
+	if !ok || j.finished() {
+		return nil, false
+	}
+	fn(j)
+	j.UpdatedAt = time.Now()
+	if j.finished() {
+		j.FinishedAt = j.UpdatedAt
+		delete(js.cancels, id)
+	}
+	js.persistLocked(j)
+	c := *j
+	return &c, true
+}
+
+// cancel stops a running job.
+func (js *JobStore) cancel(id, reason string) (*Job, bool) {
+	js.mu.Lock()
+	cancel := js.cancels[id]
+	js.mu.Unlock()
+
+	j, ok := js.update(id, func(j *Job) {
+		j.State = pb.JobState_JOB_CANCELLED
+		j.ErrorCode = codes.Canceled
+		j.Error = reason
+	})
+	if ok && cancel != nil {
+		cancel()
+	}
+	return j, ok
+}
+
+type jobKey struct{}
+
+// polling records the upstream status URL of the job running in ctx.
+func (js *JobStore) polling(ctx context.Context, location string) {
+	id, ok := ctx.Value(jobKey{}).(string)
+	if js == nil || !ok {
+		return
+	}
+	js.update(id, func(j *Job) { j.PollURL = location })
+}
+
+type jobOwnerKey struct{}
+
+// withJobOwner limits the job RPCs in ctx to the caller's own jobs.
+func withJobOwner(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, jobOwnerKey{}, caller)
+}
+
+// visibleJob reports whether the job RPCs in ctx may see j.
+func visibleJob(ctx context.Context, j *Job) bool {
+	owner, ok := ctx.Value(jobOwnerKey{}).(string)
+	return !ok || j.Caller == owner
+}
+
+// startJob runs the call in the background and returns its job at once.
+func (s *SkillServer) startJob(ctx context.Context, a *RunningAction) (*pb.ExecuteActionResponse, error) {
+	if s.Jobs == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "action '%s' cannot run as a job, no job store is configured", a.Name)
+	}
+	now := time.Now()
+	job := &Job{
+		ID:        uuid.New().String(),
+		Action:    a.Name,
+		State:     pb.JobState_JOB_RUNNING,
+		Caller:    CallerFromContext(ctx).String(),
+		RequestID: RequestIDFromContext(ctx),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	// The job belongs to the store once it runs, answer with a snapshot
+	started := job.toPB()
+	s.runJob(ctx, job, a, func(ctx context.Context) (*pb.ExecuteActionResponse, error) {
+		return s.runAction(ctx, a)
+	})
+	slog.InfoContext(ctx, "Action started as a job", "action", a.Name, "job", started.Id)
+
+	return &pb.ExecuteActionResponse{
+		Response: fmt.Sprintf("Action %s is running as job %s. Call GetJob with this ID for its outcome.", a.Name, started.Id),
+		Job:      started,
+	}, nil
+}
+
+// runJob stores the job and runs it detached from the caller's deadline,
+// keeping the request ID, caller and trace of ctx.
+func (s *SkillServer) runJob(ctx context.Context, job *Job, a *RunningAction, run func(context.Context) (*pb.ExecuteActionResponse, error)) {
+	ctx = context.WithValue(context.WithoutCancel(ctx), jobKey{}, job.ID)
+	ctx, cancel := context.WithTimeout(ctx, a.jobTimeout())
+	s.Jobs.add(job, cancel)
+
+	go func() {
+		defer cancel()
+		start := time.Now()
+		res, err := run(ctx)
+
+		finished, ok := s.Jobs.update(job.ID, func(j *Job) {
+			j.Response = res.GetResponse()
+			if e := res.GetError(); err == nil && e != nil {
+				j.State = pb.JobState_JOB_FAILED
+				j.ErrorCode, j.Error = codes.Code(e.GetCode()), e.GetMessage()
+				return
+			}
+			if err == nil {
+				j.State = pb.JobState_JOB_SUCCEEDED
+				return
+			}
+			st, ok := status.FromError(err)
+			if !ok {
+				st = status.FromContextError(err)
+			}
+			j.State = pb.JobState_JOB_FAILED
+			j.ErrorCode, j.Error = st.Code(), st.Message()
+		})
+		if !ok {
+			// Cancelled while running
+			finished, _ = s.Jobs.Get(job.ID)
+		}
+		slog.InfoContext(ctx, "Job finished", "action", job.Action, "job", job.ID, "state", finished.State.String())
+
+		if s.Audit != nil {
+			e := newAuditEntry(ctx, job.Action, nil, a, res, err, start)
+			e.JobID = job.ID
+			if finished.State == pb.JobState_JOB_CANCELLED {
+				e.Outcome, e.Error = AuditCancelled, finished.Error
+			}
+			s.Audit.write(ctx, e)
+		}
+		s.notifyJob(ctx, finished)
+	}()
+}
+
+// ResumeJobs continues polling the upstream for jobs that were waiting on
+// one when the skill stopped.
+func (s *SkillServer) ResumeJobs() {
+	for _, job := range s.Jobs.List("", pb.JobState_JOB_RUNNING) {
+		def, ok := s.ActionsMap[job.Action]
+		var a *RunningAction
+		var err error
+		switch {
+		case !ok || def.Async == nil:
+			err = fmt.Errorf("action '%s' no longer polls its upstream", job.Action)
+		default:
+			a, err = s.baseRunningAction(job.Action, def)
+		}
+		if err != nil {
+			s.Jobs.update(job.ID, func(j *Job) {
+				j.State, j.ErrorCode, j.Error = pb.JobState_JOB_FAILED, codes.Aborted, err.Error()
+			})
+			continue
+		}
+
+		ctx := WithRequestID(context.Background(), job.RequestID)
+		slog.InfoContext(ctx, "Resuming job", "action", job.Action, "job", job.ID)
+		location := job.PollURL
+		s.runJob(ctx, job, a, func(ctx context.Context) (*pb.ExecuteActionResponse, error) {
+			res, err := s.poll(ctx, a, location, 0)
+			if err != nil {
+				return nil, err
+			}
+			a.StatusCode = res.StatusCode
+			return s.render(ctx, a, res, false)
+		})
+	}
+}
+
+// notifyJob POSTs the finished job to the configured webhook. Failures are
+// logged; the job's outcome is still available from GetJob.
+func (s *SkillServer) notifyJob(ctx context.Context, job *Job) {
+	cfg := s.Jobs.Config
+	if cfg.CallbackURL == "" {
+		return
+	}
+	body, err := protojson.Marshal(job.toPB())
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, DefaultCallbackTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.CallbackURL, bytes.NewReader(body))
+	if err != nil {
+		slog.ErrorContext(ctx, "Invalid job callback URL", "error", err)
+		return
+	}
+	resolver := s.Secrets
+	if resolver == nil {
+		resolver = defaultResolver
+	}
+	for key, value := range cfg.CallbackHeaders {
+		value, err := resolver.Expand(ctx, value)
+		if err != nil {
+			slog.ErrorContext(ctx, "Could not resolve job callback header", "header", key, "error", err)
+			return
+		}
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	guard, transport := s.Guard, s.Transport
+	if guard == nil {
+		guard = defaultNetGuard
+	}
+	if transport == nil {
+		transport = defaultTransport
+	}
+	resp, err := guard.Do(req, transport, false)
+	if err != nil {
+		slog.WarnContext(ctx, "Job callback failed", "job", job.ID, "error", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		slog.WarnContext(ctx, "Job callback rejected", "job", job.ID, "status", resp.StatusCode)
+	}
+}
+
+// GetJob RPC implementation.
+func (s *SkillServer) GetJob(ctx context.Context, req *pb.GetJobRequest) (*pb.Job, error) {
+	j, ok := s.Jobs.Get(req.Id)
+	if !ok || !visibleJob(ctx, j) {
+		return nil, status.Errorf(codes.NotFound, "job '%s' not found", req.Id)
+	}
+	return j.toPB(), nil
+}
+
+// CancelJob RPC implementation. Work an upstream has already accepted is
+// not cancelled there; the skill only stops following it.
+func (s *SkillServer) CancelJob(ctx context.Context, req *pb.CancelJobRequest) (*pb.Job, error) {
+	j, ok := s.Jobs.Get(req.Id)
+	if !ok || !visibleJob(ctx, j) {
+		return nil, status.Errorf(codes.NotFound, "job '%s' not found", req.Id)
+	}
+	cancelled, ok := s.Jobs.cancel(req.Id, "cancelled by "+CallerFromContext(ctx).String())
+	if !ok {
+		return nil, status.Errorf(codes.FailedPrecondition, "job '%s' has already finished", req.Id)
+	}
+	slog.InfoContext(ctx, "Job cancelled", "action", cancelled.Action, "job", cancelled.ID)
+	return cancelled.toPB(), nil
+}
+
+// ListJobs RPC implementation.
+func (s *SkillServer) ListJobs(ctx context.Context, req *pb.ListJobsRequest) (*pb.ListJobsResponse, error) {
+	res := &pb.ListJobsResponse{}
+	for _, j := range s.Jobs.List(req.Action, req.State) {
+		if visibleJob(ctx, j) {
+			res.Jobs = append(res.Jobs, j.toPB())
+		}
+	}
+	return res, nil
+}
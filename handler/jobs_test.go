@@ -0,0 +1,86 @@
+package skill
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	pb "yafai-skill/proto"
+)
+
+func TestJobStorePersistsResponsesOnlyEncrypted(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32))
+	for _, tt := range []struct {
+		name     string
+		key      string
+		file     string
+		response string
+	}{
+		{name: "without key", file: "job1.json"},
+		{name: "with key", key: key, file: "job1.enc", response: "order 42 for alice@example.com"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			cfg := JobsConfig{Dir: dir, Key: tt.key}
+			js, err := NewJobStore(context.Background(), cfg, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			now := time.Now()
+			js.add(&Job{
+				ID:         "job1",
+				Action:     "GetOrder",
+				State:      pb.JobState_JOB_SUCCEEDED,
+				Response:   "order 42 for alice@example.com",
+				CreatedAt:  now,
+				FinishedAt: now,
+			}, func() {})
+
+			b, err := os.ReadFile(filepath.Join(dir, tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bytes.Contains(b, []byte("alice@example.com")) {
+				t.Errorf("%s holds the response in plaintext", tt.file)
+			}
+
+			reloaded, err := NewJobStore(context.Background(), cfg, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			j, ok := reloaded.Get("job1")
+			if !ok {
+				t.Fatal("job not reloaded")
+			}
+			if j.State != pb.JobState_JOB_SUCCEEDED || j.Response != tt.response {
+				t.Errorf("reloaded job is %s with response %q, want JOB_SUCCEEDED with %q", j.State, j.Response, tt.response)
+			}
+		})
+	}
+}
+
+func TestJobStoreRewritesPlaintextJobs(t *testing.T) {
+	dir := t.TempDir()
+	old := `{"id":"old","action":"GetOrder","state":2,"response":"alice@example.com","finished_at":"` + time.Now().Format(time.RFC3339Nano) + `"}`
+	if err := os.WriteFile(filepath.Join(dir, "old.json"), []byte(old), 0600); err != nil {
+		t.Fatal(err)
+	}
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{7}, 32))
+	if _, err := NewJobStore(context.Background(), JobsConfig{Dir: dir, Key: key}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old.json")); !os.IsNotExist(err) {
+		t.Errorf("plaintext job file was kept: %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "old.enc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, []byte("alice@example.com")) {
+		t.Error("rewritten job holds the response in plaintext")
+	}
+}
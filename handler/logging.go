@@ -0,0 +1,82 @@
+package skill
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDHeader is the metadata key carrying the request ID in both
+// directions. Callers may set it to correlate their own logs.
+const RequestIDHeader = "x-request-id"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID, or "" outside a request.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// requestID takes the caller's request ID from metadata or generates one,
+// and sends it back in the response header.
+func requestID(ctx context.Context) context.Context {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(RequestIDHeader); len(v) > 0 && len(v[0]) <= 128 {
+			id = v[0]
+		}
+	}
+	if id == "" {
+		id = uuid.New().String()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDHeader, id))
+	return WithRequestID(ctx, id)
+}
+
+// RequestIDUnaryInterceptor attaches a request ID to every call. It should
+// run first so every later log line carries the ID.
+func RequestIDUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(requestID(ctx), req)
+	}
+}
+
+// RequestIDStreamInterceptor is the streaming counterpart of RequestIDUnaryInterceptor.
+func RequestIDStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextStream{ServerStream: ss, ctx: requestID(ss.Context())})
+	}
+}
+
+// ContextHandler adds the request ID and caller from the context to each
+// record logged with a *Context slog call.
+type ContextHandler struct {
+	slog.Handler
+}
+
+func (h ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestIDFromContext(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if c, ok := ctx.Value(callerKey{}).(Caller); ok {
+		r.AddAttrs(slog.String("caller", c.String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return ContextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h ContextHandler) WithGroup(name string) slog.Handler {
+	return ContextHandler{h.Handler.WithGroup(name)}
+}
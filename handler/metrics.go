@@ -0,0 +1,166 @@
+package skill
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	pb "yafai-skill/proto"
+
+	grpcprom "github.com/grpc-ecosystem/go-grpc-middleware/providers/prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/status"
+)
+
+// Metrics records Prometheus metrics for action calls. A nil *Metrics is
+// valid and records nothing, so call sites need no checks.
+type Metrics struct {
+	GRPC *grpcprom.ServerMetrics // Standard gRPC server metrics, wired in as interceptors
+
+	requests          *prometheus.CounterVec
+	duration          *prometheus.HistogramVec
+	inFlight          *prometheus.GaugeVec
+	upstreamDuration  *prometheus.HistogramVec
+	upstreamResponses *prometheus.CounterVec
+	upstreamRetries   *prometheus.CounterVec
+	cacheLookups      *prometheus.CounterVec
+	templateFailures  *prometheus.CounterVec
+	breakers          *prometheus.GaugeVec
+}
+
+// NewMetrics creates the skill engine's collectors and registers them.
+func NewMetrics(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		GRPC: grpcprom.NewServerMetrics(grpcprom.WithServerHandlingTimeHistogram()),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "yafai_skill_action_requests_total",
+			Help: "ExecuteAction calls by action, outcome and error code.",
+		}, []string{"action", "outcome", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "yafai_skill_action_duration_seconds",
+			Help:    "Time to handle ExecuteAction, including limits, cache and templates.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"action"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "yafai_skill_actions_in_flight",
+			Help: "ExecuteAction calls currently being handled.",
+		}, []string{"action"}),
+		upstreamDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "yafai_skill_upstream_request_duration_seconds",
+			Help:    "Time of the upstream HTTP request, including reading the body.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"action"}),
+		upstreamResponses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "yafai_skill_upstream_responses_total",
+			Help: "Upstream HTTP responses by status code, or \"error\" when none was received.",
+		}, []string{"action", "status"}),
+		upstreamRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "yafai_skill_upstream_retries_total",
+			Help: "Follow-up upstream requests sent within a single call, such as redirect hops.",
+		}, []string{"action"}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "yafai_skill_cache_lookups_total",
+			Help: "Response cache lookups by result: hit, revalidated or miss.",
+		}, []string{"action", "result"}),
+		templateFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "yafai_skill_template_render_failures_total",
+			Help: "Response templates that failed to parse or execute.",
+		}, []string{"action", "template"}),
+		breakers: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "yafai_skill_circuit_breaker_state",
+			Help: "Circuit breaker state by key: 1 for the current state, 0 for the others.",
+		}, []string{"key", "state"}),
+	}
+	collectors := []prometheus.Collector{
+		m.GRPC, m.requests, m.duration, m.inFlight, m.upstreamDuration,
+		m.upstreamResponses, m.upstreamRetries, m.cacheLookups, m.templateFailures, m.breakers,
+	}
+	for _, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// startAction marks a call in flight and returns a func recording its outcome.
+func (m *Metrics) startAction(action string) func(*pb.ExecuteActionResponse, error) {
+	if m == nil {
+		return func(*pb.ExecuteActionResponse, error) {}
+	}
+	start := time.Now()
+	m.inFlight.WithLabelValues(action).Inc()
+	return func(res *pb.ExecuteActionResponse, err error) {
+		m.inFlight.WithLabelValues(action).Dec()
+		m.duration.WithLabelValues(action).Observe(time.Since(start).Seconds())
+
+		outcome := "success"
+		// ErrorCode shares its numbering with the gRPC status codes
+		code := pb.ErrorCode(status.Code(err)).String()
+		switch {
+		case err != nil:
+			outcome = "error"
+		case res.GetError() != nil:
+			outcome, code = "error", res.GetError().GetCode().String()
+		case res.GetApproval() != nil:
+			outcome = "pending_approval"
+		}
+		m.requests.WithLabelValues(action, outcome, code).Inc()
+	}
+}
+
+// observeUpstream records one upstream call. status is the final HTTP status,
+// or 0 when the request failed, and attempts the requests it took.
+func (m *Metrics) observeUpstream(action string, status int, elapsed time.Duration, attempts int) {
+	if m == nil {
+		return
+	}
+	code := "error"
+	if status != 0 {
+		code = strconv.Itoa(status)
+	}
+	m.upstreamDuration.WithLabelValues(action).Observe(elapsed.Seconds())
+	m.upstreamResponses.WithLabelValues(action, code).Inc()
+	if attempts > 1 {
+		m.upstreamRetries.WithLabelValues(action).Add(float64(attempts - 1))
+	}
+}
+
+func (m *Metrics) cacheLookup(action, result string) {
+	if m == nil {
+		return
+	}
+	m.cacheLookups.WithLabelValues(action, result).Inc()
+}
+
+func (m *Metrics) templateFailure(action, template string) {
+	if m == nil {
+		return
+	}
+	m.templateFailures.WithLabelValues(action, template).Inc()
+}
+
+// breakerState records the state of the circuit breaker for key.
+func (m *Metrics) breakerState(key, state string) {
+	if m == nil {
+		return
+	}
+	for _, s := range []string{BreakerClosed, BreakerOpen, BreakerHalfOpen} {
+		v := 0.0
+		if s == state {
+			v = 1
+		}
+		m.breakers.WithLabelValues(key, s).Set(v)
+	}
+}
+
+// countingTransport counts the requests sent through it.
+type countingTransport struct {
+	next  http.RoundTripper
+	count *int
+}
+
+func (t countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	*t.count++
+	return t.next.RoundTrip(req)
+}
@@ -0,0 +1,192 @@
+package skill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// maxRedirects matches the net/http default redirect limit.
+const maxRedirects = 10
+
+// blockedNetworks are address ranges outbound calls may not reach unless the
+// manifest lists them in allowed_networks.
+var blockedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",      // "this" network
+	"10.0.0.0/8",     // private
+	"100.64.0.0/10",  // carrier-grade NAT
+	"127.0.0.0/8",    // loopback
+	"169.254.0.0/16", // link-local, cloud metadata endpoints
+	"172.16.0.0/12",  // private
+	"192.168.0.0/16", // private
+	"::/128",         // unspecified
+	"::1/128",        // loopback
+	"fc00::/7",       // unique local
+	"fe80::/10",      // link-local
+)
+
+// defaultNetGuard applies the private range blocking when no guard is configured.
+var defaultNetGuard, _ = NewNetGuard(nil, nil)
+
+// NetGuard restricts where outbound action requests may go.
+type NetGuard struct {
+	AllowedHosts    []string     // Host names or "*.domain" wildcards; empty allows any public host
+	AllowedNetworks []*net.IPNet // Blocked ranges that are explicitly allowed
+
+	// ResolveHosts checks the addresses a URL's host resolves to before the
+	// request is sent. Set when requests go through a proxy, where the dialer
+	// only ever sees the proxy's address.
+	ResolveHosts bool
+}
+
+// NewNetGuard builds a guard from the manifest's allowed_hosts and allowed_networks.
+func NewNetGuard(hosts []string, networks []string) (*NetGuard, error) {
+	g := &NetGuard{}
+	for _, h := range hosts {
+		g.AllowedHosts = append(g.AllowedHosts, strings.ToLower(strings.TrimSpace(h)))
+	}
+	for _, n := range networks {
+		_, cidr, err := net.ParseCIDR(n)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed_networks entry %q: %w", n, err)
+		}
+		g.AllowedNetworks = append(g.AllowedNetworks, cidr)
+	}
+	return g, nil
+}
+
+// CheckURL rejects URLs with an unsupported scheme, a host outside the
+// allowlist or a blocked IP address, and with ResolveHosts set, hosts
+// resolving to blocked addresses.
+func (g *NetGuard) CheckURL(ctx context.Context, u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme %q is not allowed", u.Scheme)
+	}
+	if u.User != nil {
+		return errors.New("URLs with user info are not allowed")
+	}
+	if u.Hostname() == "" {
+		return errors.New("URL has no host")
+	}
+	if err := g.CheckHost(u.Hostname()); err != nil {
+		return err
+	}
+	// The dialer checks addresses too, but not when a proxy makes the connection
+	if ip := net.ParseIP(u.Hostname()); ip != nil {
+		return g.CheckIP(ip)
+	}
+	if g.ResolveHosts {
+		return g.checkResolved(ctx, u.Hostname())
+	}
+	return nil
+}
+
+// checkResolved runs every address host resolves to through CheckIP.
+func (g *NetGuard) checkResolved(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if err := g.CheckIP(addr.IP); err != nil {
+			return fmt.Errorf("host %q: %w", host, err)
+		}
+	}
+	return nil
+}
+
+// CheckHost rejects a host outside the allowlist.
+func (g *NetGuard) CheckHost(host string) error {
+	host = strings.ToLower(host)
+	if len(g.AllowedHosts) == 0 {
+		return nil
+	}
+	for _, allowed := range g.AllowedHosts {
+		if allowed == host {
+			return nil
+		}
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok && strings.HasSuffix(host, "."+suffix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("host %q is not in allowed_hosts", host)
+}
+
+// CheckIP rejects private, loopback and link-local addresses that are not explicitly allowed.
+func (g *NetGuard) CheckIP(ip net.IP) error {
+	for _, n := range g.AllowedNetworks {
+		if n.Contains(ip) {
+			return nil
+		}
+	}
+	if ip.IsMulticast() || ip.IsUnspecified() {
+		return fmt.Errorf("address %s is not allowed", ip)
+	}
+	for _, n := range blockedNetworks {
+		if n.Contains(ip) {
+			return fmt.Errorf("address %s is in blocked range %s", ip, n)
+		}
+	}
+	return nil
+}
+
+// Control is installed on the transport's dialer. It runs after DNS resolution
+// for every connection, so the check also covers redirects and names that
+// resolve to internal addresses.
+func (g *NetGuard) Control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("unexpected dial address %q", address)
+	}
+	return g.CheckIP(ip)
+}
+
+// Client returns an HTTP client over the shared transport that enforces the
+// guard on the request and every redirect hop. With followRedirects false the
+// redirect response itself is returned to the caller.
+func (g *NetGuard) Client(transport http.RoundTripper, followRedirects bool) *http.Client {
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !followRedirects {
+				return http.ErrUseLastResponse
+			}
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			if err := g.CheckURL(req.Context(), req.URL); err != nil {
+				return fmt.Errorf("redirect blocked: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// Do checks the request URL and sends it through the guarded client.
+func (g *NetGuard) Do(req *http.Request, transport http.RoundTripper, followRedirects bool) (*http.Response, error) {
+	if err := g.CheckURL(req.Context(), req.URL); err != nil {
+		return nil, fmt.Errorf("request blocked: %w", err)
+	}
+	return g.Client(transport, followRedirects).Do(req)
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
@@ -0,0 +1,25 @@
+package skill
+
+import (
+	"net"
+	"syscall"
+)
+
+func peerCredentials(conn *net.UnixConn) (UnixPeerInfo, error) {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return UnixPeerInfo{}, err
+	}
+	var cred *syscall.Ucred
+	var credErr error
+	err = raw.Control(func(fd uintptr) {
+		cred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err != nil {
+		return UnixPeerInfo{}, err
+	}
+	if credErr != nil {
+		return UnixPeerInfo{}, credErr
+	}
+	return UnixPeerInfo{UID: int(cred.Uid), GID: int(cred.Gid), PID: int(cred.Pid)}, nil
+}
@@ -0,0 +1,304 @@
+package skill
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	pb "yafai-skill/proto"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"gopkg.in/yaml.v3"
+)
+
+// Policy decides which actions each caller may see and execute.
+//
+//	default: deny
+//	rules:
+//	  - callers: ["token:crm-agent", "unix:alice"]
+//	    skills: ["hubspot"]
+//	    actions: ["get*", "search*"]
+//	    methods: [GET]
+//	    constraints:
+//	      objectType: [contacts]
+//	  - callers: ["mtls:ops"]
+//	    actions: ["*"]
+//	    admin: true
+//
+// A call is allowed when any rule matching the caller allows it. Callers not
+// matched by any rule get the default, which is deny.
+type Policy struct {
+	Default string        `yaml:"default"` // "allow" or "deny" for callers no rule names
+	Rules   []*PolicyRule `yaml:"rules"`
+
+	Audit *AuditLog `yaml:"-"` // Records refused calls; nil records nothing
+}
+
+// PolicyRule grants a set of callers access to actions. Empty lists match
+// everything except Callers, which must be set.
+type PolicyRule struct {
+	Callers     []string            `yaml:"callers"`     // "method:id", "id" or globs such as "token:*"
+	Skills      []string            `yaml:"skills"`      // Skill names, glob patterns allowed
+	Actions     []string            `yaml:"actions"`     // Action names, glob patterns allowed
+	Methods     []string            `yaml:"methods"`     // HTTP methods of the actions
+	Constraints map[string][]string `yaml:"constraints"` // Allowed values per argument
+	Admin       bool                `yaml:"admin"`       // Grants the approval, cache and breaker RPCs and every caller's jobs
+}
+
+// LoadPolicy reads and validates a policy file.
+func LoadPolicy(file string) (*Policy, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading policy: %w", err)
+	}
+	var p Policy
+	if err := yaml.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("parsing policy: %w", err)
+	}
+	switch p.Default {
+	case "":
+		p.Default = "deny"
+	case "allow", "deny":
+	default:
+		return nil, fmt.Errorf("policy default must be 'allow' or 'deny', got %q", p.Default)
+	}
+	for i, r := range p.Rules {
+		if len(r.Callers) == 0 {
+			return nil, fmt.Errorf("policy rule %d names no callers", i+1)
+		}
+		patterns := slices.Concat(r.Callers, r.Skills, r.Actions)
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("policy rule %d: bad pattern %q", i+1, pattern)
+			}
+		}
+		for j, m := range r.Methods {
+			r.Methods[j] = strings.ToUpper(m)
+		}
+	}
+	return &p, nil
+}
+
+func matchAny(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *PolicyRule) matchesCaller(c Caller) bool {
+	return matchCaller(r.Callers, c)
+}
+
+// matchCaller reports whether c matches any of the "method:id" or "id"
+// patterns.
+func matchCaller(patterns []string, c Caller) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, c.String()); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, c.ID); ok && !strings.Contains(p, ":") {
+			return true
+		}
+	}
+	return false
+}
+
+// rules returns the rules naming the caller, or nil when none do.
+func (p *Policy) rules(c Caller) []*PolicyRule {
+	var rules []*PolicyRule
+	for _, r := range p.Rules {
+		if r.matchesCaller(c) {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// Visible reports whether the caller may use the action at all, ignoring
+// argument constraints.
+func (p *Policy) Visible(c Caller, skill, action, method string) bool {
+	rules := p.rules(c)
+	if rules == nil {
+		return p.Default == "allow"
+	}
+	for _, r := range rules {
+		if matchAny(r.Skills, skill) && matchAny(r.Actions, action) && (len(r.Methods) == 0 || slices.Contains(r.Methods, strings.ToUpper(method))) {
+			return true
+		}
+	}
+	return false
+}
+
+// Allow checks an action call, including its arguments, against the policy.
+func (p *Policy) Allow(c Caller, skill, action, method string, args map[string]any) error {
+	rules := p.rules(c)
+	if rules == nil {
+		if p.Default == "allow" {
+			return nil
+		}
+		return status.Errorf(codes.PermissionDenied, "caller %s is not allowed to execute '%s'", c, action)
+	}
+	var violation string
+	for _, r := range rules {
+		if !matchAny(r.Skills, skill) || !matchAny(r.Actions, action) || (len(r.Methods) > 0 && !slices.Contains(r.Methods, strings.ToUpper(method))) {
+			continue
+		}
+		if v := r.violation(args); v != "" {
+			violation = v
+			continue
+		}
+		return nil
+	}
+	if violation != "" {
+		return status.Errorf(codes.PermissionDenied, "caller %s may not execute '%s' with %s", c, action, violation)
+	}
+	return status.Errorf(codes.PermissionDenied, "caller %s is not allowed to execute '%s'", c, action)
+}
+
+// violation describes the first argument outside the rule's constraints.
+// Arguments the call does not pass are left to the action's own validation.
+func (r *PolicyRule) violation(args map[string]any) string {
+	for name, allowed := range r.Constraints {
+		v, ok := args[name]
+		if !ok {
+			continue
+		}
+		if !slices.Contains(allowed, fmt.Sprint(v)) {
+			return fmt.Sprintf("%s=%v", name, v)
+		}
+	}
+	return ""
+}
+
+// Admin reports whether the caller may use the operator RPCs.
+func (p *Policy) Admin(c Caller) bool {
+	rules := p.rules(c)
+	if rules == nil {
+		return p.Default == "allow"
+	}
+	return slices.ContainsFunc(rules, func(r *PolicyRule) bool { return r.Admin })
+}
+
+// callArgs collects the arguments of a request for the constraint checks.
+// Each name may appear in one location only, and a declared param only in
+// the location it is declared in, so the value checked is the value sent.
+func callArgs(req *pb.ExecuteActionRequest, def *Action) (map[string]any, error) {
+	declared := make(map[string]string)
+	if def != nil {
+		for _, p := range def.Params {
+			declared[p.Name] = strings.ToLower(p.In)
+		}
+	}
+	args := make(map[string]any)
+	locations := map[string]*structpb.Struct{"body": req.GetBodyParams(), "query": req.GetQueryParams(), "path": req.GetPathParams()}
+	for in, s := range locations {
+		for k, v := range s.AsMap() {
+			if _, dup := args[k]; dup {
+				return nil, status.Errorf(codes.InvalidArgument, "param '%s' is given in more than one location", k)
+			}
+			if want := declared[k]; want != "" && want != in {
+				return nil, status.Errorf(codes.InvalidArgument, "param '%s' must be passed as a %s param", k, want)
+			}
+			args[k] = v
+		}
+	}
+	return args, nil
+}
+
+// allowCall checks a call against the policy. Refused calls, including ones
+// naming no action, get a rejected audit entry under workflow, which is empty
+// unless the call is a workflow step.
+func (p *Policy) allowCall(ctx context.Context, caller Caller, skill string, actions map[string]*Action, r *pb.ExecuteActionRequest, workflow string) error {
+	var method string
+	def, ok := actions[r.Name]
+	if ok {
+		method = def.Method
+	}
+	args, err := callArgs(r, def)
+	if err != nil {
+		p.Audit.refused(ctx, r, workflow, err)
+		return err
+	}
+	if err := p.Allow(caller, skill, r.Name, method, args); err != nil {
+		slog.WarnContext(ctx, "Policy denied action", "action", r.Name)
+		p.Audit.refused(ctx, r, workflow, err)
+		return err
+	}
+	if !ok {
+		// Checked after the policy so unknown and hidden actions look alike to callers without access
+		err := status.Errorf(codes.NotFound, "action '%s' not found", r.Name)
+		p.Audit.refused(ctx, r, workflow, err)
+		return err
+	}
+	return nil
+}
+
+// UnaryInterceptor enforces the policy on the SkillService RPCs. It must run
+// after the Authenticator so the caller is known.
+func (p *Policy) UnaryInterceptor(skill string, actions map[string]*Action) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !strings.HasPrefix(info.FullMethod, "/skill.SkillService/") {
+			return handler(ctx, req)
+		}
+		caller := CallerFromContext(ctx)
+
+		switch r := req.(type) {
+		case *pb.GetActionRequest:
+			res, err := handler(ctx, req)
+			if err != nil {
+				return res, err
+			}
+			out := res.(*pb.GetActionsResponse)
+			out.Actions = slices.DeleteFunc(out.Actions, func(a *pb.Action) bool {
+				return !p.Visible(caller, skill, a.Name, a.Method)
+			})
+			return out, nil
+
+		case *pb.ExecuteActionRequest:
+			if err := p.allowCall(ctx, caller, skill, actions, r, ""); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+
+		case *pb.BatchExecuteActionsRequest:
+			// The whole batch is refused if any call in it is
+			for _, item := range r.Requests {
+				if err := p.allowCall(ctx, caller, skill, actions, item, ""); err != nil {
+					return nil, err
+				}
+			}
+			return handler(ctx, req)
+
+		case *pb.ApproveActionRequest, *pb.RejectActionRequest, *pb.ListPendingApprovalsRequest:
+			// Decided by the handlers, which also accept the --approvers callers
+			return handler(ctx, req)
+
+		case *pb.GetJobRequest, *pb.CancelJobRequest, *pb.ListJobsRequest:
+			// Callers follow their own jobs, admins every job
+			if !p.Admin(caller) {
+				ctx = withJobOwner(ctx, caller.String())
+			}
+			return handler(ctx, req)
+
+		default:
+			if !p.Admin(caller) {
+				slog.WarnContext(ctx, "Policy denied call", "method", info.FullMethod)
+				return nil, status.Errorf(codes.PermissionDenied, "caller %s is not allowed to call %s", caller, info.FullMethod)
+			}
+			return handler(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package skill
+
+import (
+	"context"
+	"testing"
+
+	pb "yafai-skill/proto"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestPolicyAllowCall(t *testing.T) {
+	policy := &Policy{
+		Default: "deny",
+		Rules: []*PolicyRule{
+			{Callers: []string{"token:reader"}, Methods: []string{"GET"}},
+			{Callers: []string{"mtls:ops"}},
+			{
+				Callers:     []string{"token:agent"},
+				Actions:     []string{"list_*", "get_contact"},
+				Constraints: map[string][]string{"objectType": {"contacts"}},
+			},
+		},
+	}
+	actions := map[string]*Action{
+		"list_objects": {Name: "list_objects", Method: "GET", Params: []*Param{{Name: "objectType", In: "path"}}},
+		"get_contact":  {Name: "get_contact", Method: "GET", Params: []*Param{{Name: "objectType", In: "query"}}},
+		"delete_all":   {Name: "delete_all", Method: "DELETE"},
+	}
+	agent := Caller{ID: "agent", Method: "token"}
+	tests := []struct {
+		name   string
+		caller Caller
+		action string
+		path   map[string]any
+		query  map[string]any
+		body   map[string]any
+		code   codes.Code
+	}{
+		{name: "allowed path value", caller: agent, action: "list_objects", path: map[string]any{"objectType": "contacts"}},
+		{name: "allowed query value", caller: agent, action: "get_contact", query: map[string]any{"objectType": "contacts"}},
+		{name: "constrained param left out", caller: agent, action: "list_objects"},
+		{name: "disallowed path value", caller: agent, action: "list_objects", path: map[string]any{"objectType": "deals"}, code: codes.PermissionDenied},
+		{name: "disallowed query value", caller: agent, action: "get_contact", query: map[string]any{"objectType": "deals"}, code: codes.PermissionDenied},
+		{name: "undeclared body value", caller: agent, action: "list_objects", body: map[string]any{"objectType": "deals"}, code: codes.InvalidArgument},
+		{name: "declared path param in query", caller: agent, action: "list_objects", query: map[string]any{"objectType": "contacts"}, code: codes.InvalidArgument},
+		{name: "same param twice", caller: agent, action: "list_objects", path: map[string]any{"objectType": "contacts"}, body: map[string]any{"objectType": "deals"}, code: codes.InvalidArgument},
+		{name: "action outside rule", caller: agent, action: "delete_all", code: codes.PermissionDenied},
+		{name: "method rule", caller: Caller{ID: "reader", Method: "token"}, action: "get_contact"},
+		{name: "method outside rule", caller: Caller{ID: "reader", Method: "token"}, action: "delete_all", code: codes.PermissionDenied},
+		{name: "unknown action", caller: Caller{ID: "ops", Method: "mtls"}, action: "missing", code: codes.NotFound},
+		{name: "unknown action hidden from denied caller", caller: agent, action: "missing", code: codes.PermissionDenied},
+		{name: "caller without rules", caller: Caller{ID: "stranger", Method: "token"}, action: "list_objects", code: codes.PermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &pb.ExecuteActionRequest{Name: tt.action}
+			var err error
+			if req.PathParams, err = structpb.NewStruct(tt.path); err != nil {
+				t.Fatal(err)
+			}
+			if req.QueryParams, err = structpb.NewStruct(tt.query); err != nil {
+				t.Fatal(err)
+			}
+			if req.BodyParams, err = structpb.NewStruct(tt.body); err != nil {
+				t.Fatal(err)
+			}
+			err = policy.allowCall(context.Background(), tt.caller, "crm", actions, req, "")
+			if got := status.Code(err); got != tt.code {
+				t.Errorf("code = %v, want %v (%v)", got, tt.code, err)
+			}
+		})
+	}
+}
+
+func TestPolicyDefault(t *testing.T) {
+	stranger := Caller{ID: "stranger", Method: "token"}
+	for _, def := range []string{"allow", "deny"} {
+		p := &Policy{Default: def, Rules: []*PolicyRule{{Callers: []string{"token:agent"}}}}
+		err := p.Allow(stranger, "crm", "list_objects", "GET", nil)
+		if allowed := err == nil; allowed != (def == "allow") {
+			t.Errorf("default %s: allowed = %v", def, allowed)
+		}
+		if p.Admin(stranger) != (def == "allow") {
+			t.Errorf("default %s: admin = %v", def, p.Admin(stranger))
+		}
+	}
+}
@@ -0,0 +1,366 @@
+package skill
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// RateLimitConfig is the rate_limit section of the manifest or an action.
+type RateLimitConfig struct {
+	Rate        float64 `yaml:"rate"`          // Requests per second; 0 disables the token bucket
+	Burst       int     `yaml:"burst"`         // Bucket size, defaults to max(1, rate)
+	MaxInFlight int     `yaml:"max_in_flight"` // Concurrent upstream calls; 0 is unlimited
+	MaxQueue    int     `yaml:"max_queue"`     // Calls allowed to wait for a slot, defaults to 10x max_in_flight
+
+	CredentialRate  float64 `yaml:"credential_rate"`  // Requests per second per credential (skill level only)
+	CredentialBurst int     `yaml:"credential_burst"` // Bucket size per credential
+}
+
+// tokenBucket hands out reservations at a steady rate with bursts.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	b := float64(burst)
+	if b <= 0 {
+		b = math.Max(1, rate)
+	}
+	return &tokenBucket{rate: rate, burst: b, tokens: b, last: time.Now()}
+}
+
+// reserve takes a token and returns how long the caller must wait before using it.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel returns a token taken by reserve.
+func (b *tokenBucket) cancel() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
+
+// concurrencyLimit caps in-flight calls with a bounded wait queue.
+type concurrencyLimit struct {
+	slots    chan struct{}
+	maxQueue int
+
+	mu      sync.Mutex
+	queued  int
+	avgCall time.Duration // Moving average of call duration, used to estimate queue wait
+}
+
+func newConcurrencyLimit(maxInFlight, maxQueue int) *concurrencyLimit {
+	if maxInFlight <= 0 {
+		return nil
+	}
+	if maxQueue <= 0 {
+		maxQueue = 10 * maxInFlight
+	}
+	return &concurrencyLimit{slots: make(chan struct{}, maxInFlight), maxQueue: maxQueue}
+}
+
+// acquire waits for a slot, refusing when the queue is full or the estimated
+// wait would pass the caller's deadline.
+func (c *concurrencyLimit) acquire(ctx context.Context, name string) (func(), error) {
+	if c == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case c.slots <- struct{}{}:
+		return c.releaser(), nil
+	default:
+	}
+
+	c.mu.Lock()
+	if c.queued >= c.maxQueue {
+		wait := c.estimateLocked()
+		c.mu.Unlock()
+		return nil, exhausted(fmt.Sprintf("%s: %d calls already waiting for an upstream slot", name, c.maxQueue), wait)
+	}
+	wait := c.estimateLocked()
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+		c.mu.Unlock()
+		return nil, exhausted(fmt.Sprintf("%s: upstream slot not expected before the deadline", name), wait)
+	}
+	c.queued++
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		c.queued--
+		c.mu.Unlock()
+	}()
+	select {
+	case c.slots <- struct{}{}:
+		return c.releaser(), nil
+	case <-ctx.Done():
+		return nil, exhausted(fmt.Sprintf("%s: no upstream slot before the deadline", name), wait)
+	}
+}
+
+func (c *concurrencyLimit) estimateLocked() time.Duration {
+	rounds := c.queued/cap(c.slots) + 1
+	return time.Duration(rounds) * c.avgCall
+}
+
+func (c *concurrencyLimit) releaser() func() {
+	start := time.Now()
+	return func() {
+		took := time.Since(start)
+		c.mu.Lock()
+		if c.avgCall == 0 {
+			c.avgCall = took
+		} else {
+			c.avgCall = (c.avgCall*7 + took) / 8
+		}
+		c.mu.Unlock()
+		<-c.slots
+	}
+}
+
+// limitSet is the bucket and concurrency cap for one scope.
+type limitSet struct {
+	bucket      *tokenBucket
+	concurrency *concurrencyLimit
+}
+
+func newLimitSet(cfg *RateLimitConfig) *limitSet {
+	if cfg == nil {
+		return &limitSet{}
+	}
+	return &limitSet{
+		bucket:      newTokenBucket(cfg.Rate, cfg.Burst),
+		concurrency: newConcurrencyLimit(cfg.MaxInFlight, cfg.MaxQueue),
+	}
+}
+
+// RateLimiter applies the skill, action and credential limits to upstream
+// calls and backs off when the upstream reports its own limit is reached.
+type RateLimiter struct {
+	skill   *limitSet
+	actions map[string]*limitSet
+	cfg     RateLimitConfig
+
+	mu          sync.Mutex
+	credentials map[string]*tokenBucket
+	pausedUntil time.Time // Learned from rate limit response headers
+}
+
+// NewRateLimiter builds the limiter from the skill config and the actions' own limits.
+func NewRateLimiter(cfg RateLimitConfig, actions map[string]*Action) *RateLimiter {
+	l := &RateLimiter{
+		skill:       newLimitSet(&cfg),
+		actions:     make(map[string]*limitSet),
+		cfg:         cfg,
+		credentials: make(map[string]*tokenBucket),
+	}
+	for name, action := range actions {
+		if action.RateLimit != nil {
+			l.actions[name] = newLimitSet(action.RateLimit)
+		}
+	}
+	return l
+}
+
+// Acquire blocks until the call may go out and returns a function releasing
+// its concurrency slots. It fails with RESOURCE_EXHAUSTED and a retry hint
+// when the wait would pass the caller's deadline.
+func (l *RateLimiter) Acquire(ctx context.Context, a *RunningAction) (func(), error) {
+	now := time.Now()
+	action := l.actions[a.Name]
+	if action == nil {
+		action = &limitSet{}
+	}
+	credential := l.credentialBucket(ctx, a)
+
+	l.mu.Lock()
+	paused := l.pausedUntil.Sub(now)
+	l.mu.Unlock()
+
+	buckets := []*tokenBucket{l.skill.bucket, action.bucket, credential}
+	wait := max(paused, 0)
+	for _, b := range buckets {
+		wait = max(wait, b.reserve(now))
+	}
+	cancel := func() {
+		for _, b := range buckets {
+			b.cancel()
+		}
+	}
+
+	if wait > 0 {
+		if deadline, ok := ctx.Deadline(); ok && now.Add(wait).After(deadline) {
+			cancel()
+			return nil, exhausted(fmt.Sprintf("%s: rate limit allows the next call in %s, after the deadline", a.Name, wait.Round(time.Millisecond)), wait)
+		}
+		slog.InfoContext(ctx, "Rate limited, waiting", "action", a.Name, "wait", wait)
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			cancel()
+			return nil, ctx.Err()
+		}
+	}
+
+	// A call refused here never goes out, so its tokens are given back
+	releaseSkill, err := l.skill.concurrency.acquire(ctx, a.Name)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	releaseAction, err := action.concurrency.acquire(ctx, a.Name)
+	if err != nil {
+		releaseSkill()
+		cancel()
+		return nil, err
+	}
+	return func() {
+		releaseAction()
+		releaseSkill()
+	}, nil
+}
+
+// credentialBucket returns the bucket for the credential the call
+// authenticates with. Buckets are keyed by a hash of the resolved token, so
+// references to the same credential share one and the token is not kept.
+func (l *RateLimiter) credentialBucket(ctx context.Context, a *RunningAction) *tokenBucket {
+	if l.cfg.CredentialRate <= 0 || a.NoCredentials {
+		return nil
+	}
+	token, err := a.authToken(ctx)
+	if err != nil {
+		// The call fails when it resolves the token too; count it against the reference
+		token = a.AuthToken
+	}
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.credentials[key]
+	if !ok {
+		b = newTokenBucket(l.cfg.CredentialRate, l.cfg.CredentialBurst)
+		l.credentials[key] = b
+	}
+	return b
+}
+
+// Observe learns from the upstream's rate limit headers, pausing all calls
+// until the limit resets once it reports none remaining.
+func (l *RateLimiter) Observe(statusCode int, header http.Header) {
+	if header == nil {
+		return
+	}
+	var until time.Time
+	now := time.Now()
+
+	if statusCode == http.StatusTooManyRequests {
+		until = now.Add(time.Second)
+		if d, ok := parseResetHeader(header.Get("Retry-After"), now); ok {
+			until = now.Add(d)
+		}
+	}
+	for key := range header {
+		k := strings.ToLower(key)
+		if !strings.HasSuffix(k, "ratelimit-remaining") || strings.TrimSpace(header.Get(key)) != "0" {
+			continue
+		}
+		prefix := key[:len(key)-len("remaining")]
+		for _, reset := range []string{"Reset", "Interval-Milliseconds"} {
+			value := header.Get(prefix + reset)
+			if value == "" {
+				continue
+			}
+			var d time.Duration
+			var ok bool
+			if reset == "Interval-Milliseconds" {
+				ms, err := strconv.Atoi(value)
+				d, ok = time.Duration(ms)*time.Millisecond, err == nil
+			} else {
+				d, ok = parseResetHeader(value, now)
+			}
+			if ok && now.Add(d).After(until) {
+				until = now.Add(d)
+			}
+		}
+	}
+
+	if until.IsZero() {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until.After(l.pausedUntil) {
+		slog.Warn("Upstream rate limit reached, pausing calls", "until", until.Format(time.RFC3339))
+		l.pausedUntil = until
+	}
+}
+
+// parseResetHeader reads a reset hint given as seconds, a Unix timestamp or an HTTP date.
+func parseResetHeader(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if n, err := strconv.ParseFloat(value, 64); err == nil {
+		if n > 1e9 {
+			return time.Unix(int64(n), 0).Sub(now), true
+		}
+		return time.Duration(n * float64(time.Second)), true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return t.Sub(now), true
+	}
+	return 0, false
+}
+
+// exhausted builds a RESOURCE_EXHAUSTED status carrying a retry hint.
+func exhausted(msg string, retryAfter time.Duration) error {
+	retryAfter = max(retryAfter, time.Second).Round(time.Second)
+	st := status.New(codes.ResourceExhausted, fmt.Sprintf("%s, retry after %s", msg, retryAfter))
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
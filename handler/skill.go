@@ -4,23 +4,32 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"net/http"
 	"net/url"
-	"os"
 	"strconv"
 	"strings"
 	"text/template"
 	"time"
 
 	pb "yafai-skill/proto"
+	"yafai-skill/secrets"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
+// defaultResolver reads secrets from the environment when no resolver is configured.
+var defaultResolver, _ = secrets.NewResolver(nil, nil)
+
 // Struct to handle YAML unmarshal to Go objects
 func structToMap(m *structpb.Struct) map[string]interface{} {
 	result := make(map[string]interface{})
@@ -63,23 +72,23 @@ func structpbValueToInterface(v *structpb.Value) interface{} {
 
 // GetActions RPC implementation
 func (s *SkillServer) GetActions(ctx context.Context, req *pb.GetActionRequest) (res *pb.GetActionsResponse, err error) {
-	slog.Info("Received GetActions request for task: %s", req.Task)
+	slog.InfoContext(ctx, "GetActions called", "task", req.Task)
 
 	// Check if ActionsMap is populated correctly
 	toolDefinitions := s.ActionsMap // Access the parsed actions
 	if len(toolDefinitions) == 0 {
-		slog.Error("No actions found in ActionsMap")
+		slog.ErrorContext(ctx, "No actions found in ActionsMap")
 	}
 
 	actions := make([]*pb.Action, 0, len(toolDefinitions))
 	for actionName, actionDef := range toolDefinitions {
 		// Log the actionName and the action definition for debugging
-		slog.Info("Processing action: %s", actionName)
+		slog.DebugContext(ctx, "Processing action", "action", actionName)
 
 		params := make([]*pb.Parameter, len(actionDef.Params))
 		for i, p := range actionDef.Params {
 			// Log each parameter being processed
-			slog.Info("Processing param: %s", p.Name)
+			slog.DebugContext(ctx, "Processing param", "action", actionName, "param", p.Name)
 
 			params[i] = &pb.Parameter{
 				Name:        p.Name,
@@ -111,7 +120,7 @@ func (s *SkillServer) GetActions(ctx context.Context, req *pb.GetActionRequest)
 	}
 
 	// Log the final response for debugging
-	slog.Info("Returning response with %d actions", len(actions))
+	slog.InfoContext(ctx, "Returning actions", "count", len(actions))
 
 	return res, nil
 }
@@ -143,25 +152,56 @@ func convertToPBParameters(params []*Param) []*pb.Parameter {
 }
 
 func (s *SkillServer) ExecuteAction(ctx context.Context, req *pb.ExecuteActionRequest) (*pb.ExecuteActionResponse, error) {
-	//slog.Info("%+v", req)
-	reqID := uuid.New().String()
-	slog.Info("ExecuteAction called | ID: %s | Action: %s | Time: %s", reqID, req.Name, time.Now().Format(time.RFC3339Nano))
+	if RequestIDFromContext(ctx) == "" {
+		ctx = WithRequestID(ctx, uuid.New().String())
+	}
+	slog.InfoContext(ctx, "ExecuteAction called", "action", req.Name)
 
 	actionDef, ok := s.ActionsMap[req.Name]
 	if !ok {
-		return nil, fmt.Errorf("action '%s' not found", req.Name)
+		err := status.Errorf(codes.NotFound, "action '%s' not found", req.Name)
+		s.Audit.refused(ctx, req, "", err)
+		return nil, err
 	}
 
-	runningAction := RunningAction{
-		Name:             req.Name,
-		Desc:             actionDef.Desc,
-		BaseURL:          actionDef.BaseURL,
-		Method:           actionDef.Method,
-		Headers:          actionDef.Headers,
-		QueryParams:      make(map[string]interface{}),
-		BodyParams:       make(map[string]interface{}),
-		PathParams:       make(map[string]interface{}),
-		ResponseTemplate: actionDef.ResponseTemplate,
+	trace.SpanFromContext(ctx).SetAttributes(actionAttr(req.Name))
+	start := time.Now()
+	done := s.Metrics.startAction(req.Name)
+	res, runningAction, err := s.executeAction(ctx, req, actionDef)
+	done(res, err)
+	args := auditArgs(structToMap(req.PathParams), structToMap(req.QueryParams), structToMap(req.BodyParams))
+	s.Audit.record(ctx, req.Name, args, runningAction, res, err, start)
+	return res, err
+}
+
+func (s *SkillServer) executeAction(ctx context.Context, req *pb.ExecuteActionRequest, actionDef *Action) (*pb.ExecuteActionResponse, *RunningAction, error) {
+	_, span := tracer.Start(ctx, "validate", trace.WithAttributes(actionAttr(req.Name)))
+	runningAction, err := s.newRunningAction(req, actionDef)
+	endSpan(span, err)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	async := req.Async || (actionDef.Async != nil && actionDef.Async.Always)
+	if s.requiresApproval(actionDef) {
+		res, err := s.holdForApproval(ctx, runningAction, async)
+		return res, runningAction, err
+	}
+	if async {
+		res, err := s.startJob(ctx, runningAction)
+		return res, runningAction, err
+	}
+
+	res, err := s.runAction(ctx, runningAction)
+	return res, runningAction, err
+}
+
+// newRunningAction validates the request arguments against the action definition
+// and returns the call ready to be executed.
+func (s *SkillServer) newRunningAction(req *pb.ExecuteActionRequest, actionDef *Action) (*RunningAction, error) {
+	runningAction, err := s.baseRunningAction(req.Name, actionDef)
+	if err != nil {
+		return nil, err
 	}
 
 	// Convert queryParams, bodyParams, and pathParams from Struct to map
@@ -181,20 +221,20 @@ func (s *SkillServer) ExecuteAction(ctx context.Context, req *pb.ExecuteActionRe
 			if ok {
 				runningAction.QueryParams[paramDef.Name] = paramValue
 			} else if paramDef.Required {
-				return nil, fmt.Errorf("missing required query param '%s'", paramDef.Name)
+				return nil, status.Errorf(codes.InvalidArgument, "missing required query param '%s'", paramDef.Name)
 			}
 		case "path":
 			paramValue, ok = runningAction.PathParams[paramDef.Name]
 			if ok {
 				runningAction.PathParams[paramDef.Name] = paramValue
 			} else if paramDef.Required {
-				return nil, fmt.Errorf("missing required path param '%s'", paramDef.Name)
+				return nil, status.Errorf(codes.InvalidArgument, "missing required path param '%s'", paramDef.Name)
 			}
 		case "body":
 			if paramDef.In == "body" && paramDef.RootBody {
 				raw, ok := runningAction.BodyParams[paramDef.Name].([]interface{})
 				if !ok {
-					return nil, fmt.Errorf("expected array for %s", paramDef.Name)
+					return nil, status.Errorf(codes.InvalidArgument, "expected array for %s", paramDef.Name)
 				}
 				runningAction.RawBody = raw                     // store the slice
 				delete(runningAction.BodyParams, paramDef.Name) // don’t send it in BodyParams
@@ -204,73 +244,257 @@ func (s *SkillServer) ExecuteAction(ctx context.Context, req *pb.ExecuteActionRe
 			if ok {
 				runningAction.BodyParams[paramDef.Name] = paramValue
 			} else if paramDef.Required {
-				return nil, fmt.Errorf("missing required body param '%s'", paramDef.Name)
+				return nil, status.Errorf(codes.InvalidArgument, "missing required body param '%s'", paramDef.Name)
 			}
 		}
 	}
 
-	// Execute action in background with context awareness
-	resultChan := make(chan ActionResult, 1)
-	slog.Info("Log Query Params : %+v", runningAction.QueryParams)
-	slog.Info("Log Body Params : %+v", runningAction.BodyParams)
-	slog.Info("Log Path Params : %+v", runningAction.PathParams)
-	go func() {
-		runningAction.Execute(ctx, resultChan) // Pass the incoming context
-	}()
+	if actionDef.Request != nil && actionDef.Request.Transform != nil {
+		if err := runningAction.transformRequest(actionDef.Request.Transform); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkPathParams(runningAction.PathParams); err != nil {
+		return nil, err
+	}
 
-	var res ActionResult
-	select {
-	case res = <-resultChan:
-		// Action completed (successfully or with error)
-	case <-ctx.Done():
-		slog.Info("ExecuteAction cancelled: %v", ctx.Err())
-		return nil, ctx.Err()
+	if actionDef.Type == ActionExec {
+		if runningAction.Args, err = actionDef.Exec.renderArgs(runningAction.arguments()); err != nil {
+			return nil, err
+		}
 	}
 
-	if res.Error != nil {
-		failTmpl, err := template.New("fail").Parse(runningAction.ResponseTemplate.Failure)
-		if err != nil {
-			slog.Error("Template parse error: %v", err)
-			return nil, res.Error
+	return runningAction, nil
+}
+
+// baseRunningAction returns the call with the server's settings and no arguments.
+func (s *SkillServer) baseRunningAction(name string, actionDef *Action) (*RunningAction, error) {
+	guard := s.Guard
+	if guard == nil {
+		guard = defaultNetGuard
+	}
+	resolver := s.Secrets
+	if resolver == nil {
+		resolver = defaultResolver
+	}
+	transport := s.Transport
+	if transport == nil {
+		transport = defaultTransport
+	}
+	timeouts, err := actionDef.Timeouts()
+	if err != nil {
+		return nil, fmt.Errorf("action '%s': %w", name, err)
+	}
+	return &RunningAction{
+		Name:             name,
+		Desc:             actionDef.Desc,
+		Type:             actionDef.Type,
+		Steps:            actionDef.Steps,
+		Exec:             actionDef.Exec,
+		GraphQL:          actionDef.GraphQL,
+		GRPC:             actionDef.GRPC,
+		SQL:              actionDef.SQL,
+		Databases:        s.Databases,
+		Wasm:             actionDef.Wasm,
+		Hooks:            actionDef.Hooks,
+		Response:         actionDef.Response,
+		BaseURL:          actionDef.BaseURL,
+		Method:           actionDef.Method,
+		Headers:          actionDef.Headers,
+		QueryParams:      make(map[string]interface{}),
+		BodyParams:       make(map[string]interface{}),
+		PathParams:       make(map[string]interface{}),
+		ResponseTemplate: actionDef.ResponseTemplate,
+		Cache:            actionDef.Cache,
+		Async:            actionDef.Async,
+		Guard:            guard,
+		Transport:        transport,
+		Timeouts:         timeouts,
+		Secrets:          resolver,
+		AuthToken:        s.AuthToken,
+		Metrics:          s.Metrics,
+		FollowRedirects:  actionDef.FollowRedirects == nil || *actionDef.FollowRedirects,
+	}, nil
+}
+
+// runAction executes the call and renders the action's response template.
+func (s *SkillServer) runAction(ctx context.Context, runningAction *RunningAction) (*pb.ExecuteActionResponse, error) {
+	if runningAction.Type == ActionWorkflow {
+		return s.runWorkflow(ctx, runningAction)
+	}
+	res, cacheHit, err := s.fetch(ctx, runningAction)
+	if err != nil {
+		return nil, err
+	}
+	if runningAction.Async != nil && res.StatusCode == http.StatusAccepted {
+		// The upstream took the work on; follow it to the outcome
+		if res, err = s.awaitAccepted(ctx, runningAction, res); err != nil {
+			return nil, err
 		}
-		var out bytes.Buffer
-		_ = failTmpl.Execute(&out, map[string]string{"Error": res.Error.Error()})
-		return &pb.ExecuteActionResponse{Response: out.String()}, res.Error
+	}
+	runningAction.StatusCode = res.StatusCode
+
+	ctx, span := tracer.Start(ctx, "render", trace.WithAttributes(actionAttr(runningAction.Name)))
+	defer span.End()
+	return s.render(ctx, runningAction, res, cacheHit)
+}
+
+// render applies the action's success or failure template to the result.
+func (s *SkillServer) render(ctx context.Context, runningAction *RunningAction, res ActionResult, cacheHit bool) (*pb.ExecuteActionResponse, error) {
+	res = runningAction.transformResponse(res)
+	if res.Error != nil && !res.partial() {
+		return s.renderFailure(ctx, runningAction, res)
 	}
 
 	unquoted, err := strconv.Unquote(res.Result)
 	if err != nil {
-		slog.Info("Unquote failed: %v", err)
+		slog.DebugContext(ctx, "Result is not a quoted string", "action", runningAction.Name)
 		unquoted = res.Result
 	}
 
 	var data map[string]interface{}
 	if err := json.Unmarshal([]byte(unquoted), &data); err != nil {
-		slog.Warn("JSON unmarshal failed: %v, using raw result", err)
+		slog.WarnContext(ctx, "Result is not a JSON object, using raw result", "action", runningAction.Name, "error", err)
 		data = map[string]interface{}{"result": unquoted}
 	}
 
-	successTmpl, err := template.New("success").Parse(actionDef.ResponseTemplate.Success)
+	successTmpl, err := template.New("success").Parse(runningAction.ResponseTemplate.Success)
 	if err != nil {
-		slog.Error("Success template parse error: %v", err)
+		slog.ErrorContext(ctx, "Success template parse error", "action", runningAction.Name, "error", err)
+		s.templateFailed(ctx, runningAction.Name, "success", err)
 		return &pb.ExecuteActionResponse{Response: res.Result}, err // Fallback
 	}
 	var output bytes.Buffer
 	if err := successTmpl.Execute(&output, data); err != nil {
-		slog.Error("Success template execution error: %v", err)
+		if res.partial() {
+			// The data is too incomplete for the success template, report the errors alone
+			return s.renderFailure(ctx, runningAction, res)
+		}
+		slog.ErrorContext(ctx, "Success template execution error", "action", runningAction.Name, "error", err)
+		s.templateFailed(ctx, runningAction.Name, "success", err)
 		return &pb.ExecuteActionResponse{Response: res.Result}, err // Fallback
 	}
 
-	return &pb.ExecuteActionResponse{Response: output.String()}, nil
+	out := &pb.ExecuteActionResponse{Response: output.String(), CacheHit: cacheHit}
+	if res.partial() {
+		// Partial data is rendered with the success template and its errors reported alongside
+		out.Error = responseError(res.Error)
+	}
+	return out, nil
 }
 
-func (a *RunningAction) Execute(ctx context.Context, resultChan chan<- ActionResult) {
+// renderFailure applies the failure template. A reported error is returned
+// in the response rather than failing the call.
+func (s *SkillServer) renderFailure(ctx context.Context, runningAction *RunningAction, res ActionResult) (*pb.ExecuteActionResponse, error) {
+	var out bytes.Buffer
+	failTmpl, err := template.New("fail").Parse(runningAction.ResponseTemplate.Failure)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failure template parse error", "action", runningAction.Name, "error", err)
+		s.templateFailed(ctx, runningAction.Name, "failure", err)
+		if !res.Reported {
+			return nil, res.Error
+		}
+	} else if err := failTmpl.Execute(&out, map[string]string{"Error": res.Error.Error()}); err != nil {
+		s.templateFailed(ctx, runningAction.Name, "failure", err)
+	}
+	if res.Reported {
+		return &pb.ExecuteActionResponse{Response: out.String(), Error: responseError(res.Error)}, nil
+	}
+	return &pb.ExecuteActionResponse{Response: out.String()}, res.Error
+}
+
+// execute runs the call behind the upstream's circuit breaker.
+func (s *SkillServer) execute(ctx context.Context, runningAction *RunningAction) (ActionResult, error) {
+	if s.Breakers != nil {
+		key, err := s.Breakers.Allow(runningAction)
+		if err != nil {
+			return ActionResult{}, err
+		}
+		defer func() { s.Metrics.breakerState(key, s.Breakers.state(key)) }()
+		res, err := s.limitedExecute(ctx, runningAction)
+		switch {
+		case errors.Is(res.Error, context.DeadlineExceeded):
+			// The upstream did not answer within the caller's deadline
+			s.Breakers.Record(key, fmt.Errorf("no response before the deadline"))
+		case err != nil:
+			// The call never reached the upstream or the caller went away, so it says nothing about it
+			s.Breakers.Release(key)
+		default:
+			s.Breakers.Record(key, upstreamFailure(res))
+		}
+		return res, err
+	}
+	return s.limitedExecute(ctx, runningAction)
+}
+
+// limitedExecute runs the call in the background under the rate and
+// concurrency limits and waits for it or for the caller to give up. When the
+// caller gives up on a call already sent, the result's Error is the context's
+// error as well.
+func (s *SkillServer) limitedExecute(ctx context.Context, runningAction *RunningAction) (ActionResult, error) {
+	if s.Limits != nil {
+		release, err := s.Limits.Acquire(ctx, runningAction)
+		if err != nil {
+			return ActionResult{}, err
+		}
+		defer release()
+	}
+
+	// Execute action in background with context awareness
+	resultChan := make(chan ActionResult, 1)
+	slog.DebugContext(ctx, "Executing action",
+		"action", runningAction.Name,
+		"query_params", runningAction.QueryParams,
+		"body_params", runningAction.BodyParams,
+		"path_params", runningAction.PathParams,
+	)
+	go func() {
+		runningAction.Execute(ctx, resultChan) // Pass the incoming context
+	}()
+
+	select {
+	case res := <-resultChan:
+		// Action completed (successfully or with error)
+		if s.Limits != nil {
+			s.Limits.Observe(res.StatusCode, res.Header)
+		}
+		return res, nil
+	case <-ctx.Done():
+		slog.InfoContext(ctx, "ExecuteAction cancelled", "action", runningAction.Name, "error", ctx.Err())
+		return ActionResult{Error: ctx.Err()}, ctx.Err()
+	}
+}
+
+// checkPathParams rejects values that escaping leaves able to change the
+// request path: empty segments and the dot segments clients and servers
+// resolve.
+func checkPathParams(params map[string]any) error {
+	for key, value := range params {
+		switch fmt.Sprintf("%v", value) {
+		case "", ".", "..":
+			return status.Errorf(codes.InvalidArgument, "path param '%s' may not be empty, '.' or '..'", key)
+		}
+	}
+	return nil
+}
+
+// requestURL returns the action's base URL with path placeholders substituted
+// and query parameters appended.
+func (a *RunningAction) requestURL() string {
+	switch a.Type {
+	case ActionWorkflow, ActionExec, ActionWasm:
+		return ""
+	case ActionGRPC:
+		return "grpc://" + a.GRPC.Target + a.GRPC.fullMethod()
+	case ActionSQL:
+		return "sql://" + a.SQL.Database
+	}
 	u := a.BaseURL
 
-	// Replace path parameters
+	// Replace path parameters, escaped so values cannot add path segments or change the host
 	for key, value := range a.PathParams {
 		placeholder := fmt.Sprintf("{%s}", key)
-		u = strings.ReplaceAll(u, placeholder, fmt.Sprintf("%v", value)) // Use fmt.Sprintf to safely convert to string
+		u = strings.ReplaceAll(u, placeholder, url.PathEscape(fmt.Sprintf("%v", value)))
 	}
 
 	// Query params
@@ -296,75 +520,172 @@ func (a *RunningAction) Execute(ctx context.Context, resultChan chan<- ActionRes
 	if len(query) > 0 {
 		u += "?" + query.Encode()
 	}
+	return u
+}
 
-	var payload io.Reader
-
+// payload returns the encoded request body, or nil when the action sends none.
+func (a *RunningAction) payload() ([]byte, error) {
+	if a.GraphQL != nil {
+		return a.GraphQL.payload(a.BodyParams)
+	}
+	// Body params take precedence, then a raw string body, then a root body array
+	if len(a.BodyParams) > 0 {
+		return json.Marshal(a.BodyParams)
+	}
+	if a.Body != "" {
+		return []byte(a.Body), nil
+	}
 	if a.RawBody != nil {
 		// directly encode the slice or map
 		buf := &bytes.Buffer{}
 		if err := json.NewEncoder(buf).Encode(a.RawBody); err != nil {
-			resultChan <- ActionResult{Error: err}
-			return
+			return nil, err
 		}
-		payload = buf
+		return buf.Bytes(), nil
+	}
+	return nil, nil
+}
 
-	} else if len(a.BodyParams) > 0 {
-		// fallback: encode the remaining map as JSON object
-		buf := &bytes.Buffer{}
-		if err := json.NewEncoder(buf).Encode(a.BodyParams); err != nil {
-			resultChan <- ActionResult{Error: err}
-			return
-		}
-		payload = buf
+// arguments merges the call's body, query and path params.
+func (a *RunningAction) arguments() map[string]any {
+	args := make(map[string]any)
+	for _, params := range []map[string]interface{}{a.BodyParams, a.QueryParams, a.PathParams} {
+		maps.Copy(args, params)
 	}
+	return args
+}
 
-	// Handle body params
-	if len(a.BodyParams) > 0 {
-		bodyBytes, err := json.Marshal(a.BodyParams)
-		if err != nil {
-			resultChan <- ActionResult{Error: err}
-			return
-		}
-		payload = bytes.NewBuffer(bodyBytes)
-	} else if a.Body != "" {
-		payload = bytes.NewBuffer([]byte(a.Body))
+// authToken returns the bearer token for the call: the manifest's auth_token
+// reference if set, otherwise the skill key when one is configured.
+func (a *RunningAction) authToken(ctx context.Context) (string, error) {
+	if a.AuthToken != "" {
+		return a.Secrets.Expand(ctx, a.AuthToken)
+	}
+	token, err := a.Secrets.Resolve(ctx, secrets.SkillKey)
+	if errors.Is(err, secrets.ErrNotFound) {
+		return "", nil
 	}
+	return token, err
+}
+
+func (a *RunningAction) Execute(ctx context.Context, resultChan chan<- ActionResult) {
+	if a.Exec != nil {
+		a.runCommand(ctx, resultChan)
+		return
+	}
+	if a.GRPC != nil {
+		a.callGRPC(ctx, resultChan)
+		return
+	}
+	if a.SQL != nil {
+		a.runQuery(ctx, resultChan)
+		return
+	}
+	if a.Wasm != nil {
+		a.runWasm(ctx, resultChan)
+		return
+	}
+	u := a.requestURL()
+
+	body, err := a.payload()
+	if err != nil {
+		resultChan <- ActionResult{Error: err}
+		return
+	}
+	var payload io.Reader
+	if body != nil {
+		payload = bytes.NewBuffer(body)
+		slog.DebugContext(ctx, "Request payload", "action", a.Name, "body", string(body))
+	}
+	ctx, done := withTimeouts(ctx, a.Timeouts)
+	defer done()
 
-	slog.Info("Payload : %+v", payload)
 	req, err := http.NewRequestWithContext(ctx, a.Method, u, payload)
 	if err != nil {
 		resultChan <- ActionResult{Error: err}
 		return
 	}
 
-	// Set headers
+	// Set headers, resolving secret:// references at request time
 	for key, value := range a.Headers {
+		value, err := a.Secrets.Expand(ctx, value)
+		if err != nil {
+			resultChan <- ActionResult{Error: err}
+			return
+		}
 		req.Header.Set(key, value)
 	}
-	auth_key := fmt.Sprintf("Bearer %s", os.Getenv("SKILL_KEY"))
-	req.Header.Set("Authorization", auth_key)
+	if req.Header.Get("Authorization") == "" && !a.NoCredentials {
+		token, err := a.authToken(ctx)
+		if err != nil {
+			resultChan <- ActionResult{Error: err}
+			return
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
 	req.Header.Set("content-type", "application/json")
+	if a.IfNoneMatch != "" {
+		req.Header.Set("If-None-Match", a.IfNoneMatch)
+	}
+	if a.IfModifiedSince != "" {
+		req.Header.Set("If-Modified-Since", a.IfModifiedSince)
+	}
+	// The hook sees the final request, and what it returns still goes through the guard
+	if a.Hooks != nil && a.Hooks.PreRequest != nil {
+		if req, err = a.preRequest(ctx, req, body); err != nil {
+			resultChan <- ActionResult{Error: err}
+			return
+		}
+	}
 
-	client := &http.Client{Timeout: 15 * time.Second}
+	start := time.Now()
+	var statusCode, attempts int
+	defer func() {
+		a.Metrics.observeUpstream(a.Name, statusCode, time.Since(start), attempts)
+	}()
 
-	resp, err := client.Do(req)
+	// Each attempt, including redirect hops, gets a client span and a traceparent header
+	transport := otelhttp.NewTransport(countingTransport{a.Transport, &attempts},
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return "HTTP " + r.Method
+		}))
+	resp, err := a.Guard.Do(req, transport, a.FollowRedirects)
 	if err != nil {
+		if cause := context.Cause(ctx); errors.Is(cause, errReadTimeout) && !errors.Is(err, errReadTimeout) {
+			err = fmt.Errorf("%w: %w", cause, err)
+		}
 		resultChan <- ActionResult{Error: err}
 		return
 	}
 	defer resp.Body.Close()
+	statusCode = resp.StatusCode
 
-	body, err := io.ReadAll(resp.Body)
+	body, err = io.ReadAll(resp.Body)
 	if err != nil {
+		if cause := context.Cause(ctx); errors.Is(cause, errReadTimeout) && !errors.Is(err, errReadTimeout) {
+			err = fmt.Errorf("%w: %w", cause, err)
+		}
 		resultChan <- ActionResult{Error: err}
 		return
 	}
-	slog.Info("Response Body: %s", string(body))
+	slog.DebugContext(ctx, "Response body", "action", a.Name, "status", resp.StatusCode, "body", string(body))
+	if a.GraphQL != nil {
+		resultChan <- graphqlResult(resp, body)
+		return
+	}
 	if resp.StatusCode >= http.StatusBadRequest {
 		err := fmt.Errorf("HTTP error: %s, body: %s", resp.Status, string(body))
-		resultChan <- ActionResult{Error: err}
+		resultChan <- ActionResult{Error: err, StatusCode: resp.StatusCode, Header: resp.Header}
 		return
 	}
+	if a.Hooks != nil && a.Hooks.PostResponse != nil {
+		if body, err = a.postResponse(ctx, resp, body); err != nil {
+			resultChan <- ActionResult{Error: err, StatusCode: resp.StatusCode, Header: resp.Header}
+			return
+		}
+	}
 
-	resultChan <- ActionResult{Result: string(body)}
+	resultChan <- ActionResult{Result: string(body), StatusCode: resp.StatusCode, Header: resp.Header}
 }
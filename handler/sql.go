@@ -0,0 +1,457 @@
+package skill
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"yafai-skill/secrets"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // Registers the "pgx" driver
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	_ "modernc.org/sqlite" // Registers the "sqlite" driver
+)
+
+// ActionSQL runs a query against one of the manifest's databases.
+const ActionSQL = "sql"
+
+// Defaults for sql actions and their connection pools.
+const (
+	DefaultSQLMaxRows      = 1000
+	DefaultSQLMaxOpenConns = 4
+)
+
+// Supported database drivers.
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// DatabaseConfig is an entry of the manifest's databases section.
+type DatabaseConfig struct {
+	Driver       string `yaml:"driver"`         // sqlite or postgres
+	DSN          string `yaml:"dsn"`            // Data source name, e.g. secret://analytics_dsn
+	MaxOpenConns int    `yaml:"max_open_conns"` // Connections kept open at most, defaults to 4
+}
+
+// SQLSpec is the sql section of a sql action:
+//
+//	sql:
+//	  database: analytics
+//	  query: SELECT region, SUM(total) AS total FROM orders WHERE day >= :since GROUP BY region
+//	  max_rows: 100
+//
+// :name placeholders bind the call's params as query arguments; values are
+// never put into the query text.
+type SQLSpec struct {
+	Database string `yaml:"database"`  // Name in the manifest's databases section
+	Query    string `yaml:"query"`     // The statement, with :name placeholders for params
+	ReadOnly *bool  `yaml:"read_only"` // Run in a read-only transaction, defaults to true
+	MaxRows  int    `yaml:"max_rows"`  // Rows returned at most, defaults to 1000; the rest are cut off
+
+	stmt sqlStatement
+}
+
+func (q *SQLSpec) validate(params []*Param) error {
+	if q == nil || q.Database == "" || strings.TrimSpace(q.Query) == "" {
+		return fmt.Errorf("sql action needs sql.database and sql.query")
+	}
+	stmt, err := parseStatement(q.Query)
+	if err != nil {
+		return fmt.Errorf("sql query: %w", err)
+	}
+	declared := make(map[string]bool, len(params))
+	for _, p := range params {
+		declared[p.Name] = true
+	}
+	for _, name := range stmt.params {
+		if !declared[name] {
+			return fmt.Errorf("sql placeholder :%s has no param", name)
+		}
+	}
+	q.stmt = stmt
+	return nil
+}
+
+func (q *SQLSpec) readOnly() bool {
+	return q.ReadOnly == nil || *q.ReadOnly
+}
+
+func (q *SQLSpec) maxRows() int {
+	if q.MaxRows <= 0 {
+		return DefaultSQLMaxRows
+	}
+	return q.MaxRows
+}
+
+// sqlStatement is a query split around its :name placeholders.
+type sqlStatement struct {
+	parts  []string // Text around the placeholders, one more than names
+	names  []string // Placeholder at each position
+	params []string // Distinct placeholders in order of first use
+}
+
+// parseStatement finds the :name placeholders of query, skipping quoted
+// text, comments and Postgres :: casts. Driver placeholders (? and $1) are
+// rejected so every argument is bound by name.
+func parseStatement(query string) (sqlStatement, error) {
+	var st sqlStatement
+	var b strings.Builder
+	seen := make(map[string]bool)
+	for i := 0; i < len(query); {
+		c := query[i]
+		next := byte(0)
+		if i+1 < len(query) {
+			next = query[i+1]
+		}
+		switch {
+		case c == '\'' || c == '"' || c == '`':
+			end := closingQuote(query, i+1, c)
+			if end < 0 {
+				return st, fmt.Errorf("unterminated %c quote", c)
+			}
+			b.WriteString(query[i : end+1])
+			i = end + 1
+		case c == '-' && next == '-':
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			b.WriteString(query[i : i+end])
+			i += end
+		case c == '/' && next == '*':
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return st, errors.New("unterminated comment")
+			}
+			b.WriteString(query[i : i+end+4])
+			i += end + 4
+		case c == '$' && next >= '0' && next <= '9', c == '?':
+			return st, fmt.Errorf("use :name placeholders instead of %c", c)
+		case c == '$':
+			// Postgres dollar quoting: $tag$ ... $tag$
+			tagEnd := strings.IndexByte(query[i+1:], '$')
+			if tagEnd < 0 || tagEnd > 0 && !isIdent(query[i+1:i+1+tagEnd]) {
+				b.WriteByte(c)
+				i++
+				continue
+			}
+			delim := query[i : i+tagEnd+2]
+			end := strings.Index(query[i+len(delim):], delim)
+			if end < 0 {
+				return st, fmt.Errorf("unterminated %s quote", delim)
+			}
+			n := len(delim) + end + len(delim)
+			b.WriteString(query[i : i+n])
+			i += n
+		case c == ':' && next == ':':
+			b.WriteString("::")
+			i += 2
+		case c == ':' && isIdentStart(next):
+			j := i + 1
+			for j < len(query) && isIdentChar(query[j]) {
+				j++
+			}
+			name := query[i+1 : j]
+			st.parts = append(st.parts, b.String())
+			st.names = append(st.names, name)
+			if !seen[name] {
+				seen[name] = true
+				st.params = append(st.params, name)
+			}
+			b.Reset()
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	st.parts = append(st.parts, b.String())
+	return st, nil
+}
+
+// closingQuote returns the index of the quote ending the text starting at
+// from; doubled quotes are escapes.
+func closingQuote(query string, from int, quote byte) int {
+	for i := from; i < len(query); i++ {
+		if query[i] != quote {
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == quote {
+			i++
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || c >= '0' && c <= '9'
+}
+
+func isIdent(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isIdentChar(s[i]) {
+			return false
+		}
+	}
+	return s != "" && isIdentStart(s[0])
+}
+
+// text is the statement with the driver's numbered placeholders: $1 for
+// Postgres and ?1 for SQLite, numbered in the order of params.
+func (st sqlStatement) text(driver string) string {
+	prefix := "?"
+	if driver == DriverPostgres {
+		prefix = "$"
+	}
+	var b strings.Builder
+	for i, part := range st.parts {
+		b.WriteString(part)
+		if i < len(st.names) {
+			b.WriteString(prefix + strconv.Itoa(slices.Index(st.params, st.names[i])+1))
+		}
+	}
+	return b.String()
+}
+
+// args are the query arguments for the call's params. Params that were not
+// given bind NULL.
+func (st sqlStatement) args(params map[string]any) ([]any, error) {
+	args := make([]any, len(st.params))
+	for i, name := range st.params {
+		switch v := params[name].(type) {
+		case float64:
+			// JSON numbers arrive as floats; whole ones bind as integers
+			if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+				args[i] = int64(v)
+			} else {
+				args[i] = v
+			}
+		case map[string]any, []any:
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = string(b)
+		default:
+			args[i] = v
+		}
+	}
+	return args, nil
+}
+
+// Databases holds the connection pools of the manifest's databases. Pools
+// are opened on first use so a database that is down does not stop startup.
+type Databases struct {
+	configs map[string]*DatabaseConfig
+
+	mu    sync.Mutex
+	pools map[string]*sql.DB
+}
+
+// NewDatabases checks the databases section and that every sql action
+// names a database in it.
+func NewDatabases(configs map[string]*DatabaseConfig, actions map[string]*Action) (*Databases, error) {
+	for name, cfg := range configs {
+		switch cfg.Driver {
+		case DriverSQLite, DriverPostgres:
+		default:
+			return nil, fmt.Errorf("database '%s': driver must be sqlite or postgres, got %q", name, cfg.Driver)
+		}
+		if cfg.DSN == "" {
+			return nil, fmt.Errorf("database '%s' needs a dsn", name)
+		}
+	}
+	for name, action := range actions {
+		if action.SQL == nil {
+			continue
+		}
+		if _, ok := configs[action.SQL.Database]; !ok {
+			return nil, fmt.Errorf("action '%s': unknown database '%s'", name, action.SQL.Database)
+		}
+	}
+	return &Databases{configs: configs, pools: make(map[string]*sql.DB)}, nil
+}
+
+// pool returns the connection pool for the database. SQLite ignores
+// read-only transactions, so read-only calls get their own pool whose
+// connections refuse writes.
+func (d *Databases) pool(ctx context.Context, resolver *secrets.Resolver, name string, readOnly bool) (*sql.DB, *DatabaseConfig, error) {
+	cfg := d.configs[name]
+	key := name
+	if readOnly && cfg.Driver == DriverSQLite {
+		key += " (read-only)"
+	}
+
+	d.mu.Lock()
+	db, ok := d.pools[key]
+	d.mu.Unlock()
+	if ok {
+		return db, cfg, nil
+	}
+
+	// Resolving the DSN may reach a secret backend, so it is done without
+	// the lock to keep calls to other databases from waiting on it
+	dsn, err := resolver.Expand(ctx, cfg.DSN)
+	if err != nil {
+		return nil, nil, err
+	}
+	driver := "pgx"
+	if cfg.Driver == DriverSQLite {
+		driver = "sqlite"
+		if readOnly {
+			sep := "?"
+			if strings.Contains(dsn, "?") {
+				sep = "&"
+			}
+			dsn += sep + "_pragma=query_only(1)"
+		}
+	}
+	db, err = sql.Open(driver, dsn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("database '%s': %w", name, err)
+	}
+	db.SetMaxOpenConns(cmp.Or(cfg.MaxOpenConns, DefaultSQLMaxOpenConns))
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if existing, ok := d.pools[key]; ok {
+		// Another call opened the pool first
+		db.Close()
+		return existing, cfg, nil
+	}
+	d.pools[key] = db
+	return db, cfg, nil
+}
+
+// Close closes every open pool.
+func (d *Databases) Close() error {
+	if d == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var errs []error
+	for _, db := range d.pools {
+		errs = append(errs, db.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// runQuery is Execute for sql actions. The result is
+// {"columns": [...], "rows": [{column: value}], "row_count": n, "truncated": bool}.
+func (a *RunningAction) runQuery(ctx context.Context, resultChan chan<- ActionResult) {
+	spec := a.SQL
+	ctx, cancel := context.WithTimeout(ctx, a.Timeouts.Total)
+	defer cancel()
+
+	res, err := a.query(ctx)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = status.Errorf(codes.DeadlineExceeded, "query timed out after %s", a.Timeouts.Total)
+		}
+		resultChan <- ActionResult{Error: err}
+		return
+	}
+	out, err := json.Marshal(res)
+	if err != nil {
+		resultChan <- ActionResult{Error: err}
+		return
+	}
+	slog.DebugContext(ctx, "Query finished", "action", a.Name, "database", spec.Database, "rows", res.RowCount, "truncated", res.Truncated)
+	resultChan <- ActionResult{Result: string(out)}
+}
+
+type queryResult struct {
+	Columns   []string         `json:"columns"`
+	Rows      []map[string]any `json:"rows"`
+	RowCount  int              `json:"row_count"`
+	Truncated bool             `json:"truncated"`
+}
+
+func (a *RunningAction) query(ctx context.Context) (*queryResult, error) {
+	spec := a.SQL
+	readOnly := spec.readOnly()
+	db, cfg, err := a.Databases.pool(ctx, a.Secrets, spec.Database, readOnly)
+	if err != nil {
+		return nil, err
+	}
+	args, err := spec.stmt.args(a.arguments())
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: readOnly})
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "database '%s': %v", spec.Database, err)
+	}
+	defer tx.Rollback()
+	if cfg.Driver == DriverPostgres {
+		// Let the server stop the statement too, not just the client
+		timeout := fmt.Sprintf("SET LOCAL statement_timeout = %d", a.Timeouts.Total.Milliseconds())
+		if _, err := tx.ExecContext(ctx, timeout); err != nil {
+			return nil, status.Errorf(codes.Unavailable, "database '%s': %v", spec.Database, err)
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, spec.stmt.text(cfg.Driver), args...)
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "query failed: %v", err)
+	}
+	defer rows.Close()
+	res := &queryResult{Rows: []map[string]any{}}
+	if res.Columns, err = rows.Columns(); err != nil {
+		return nil, err
+	}
+	limit := spec.maxRows()
+	for rows.Next() {
+		if len(res.Rows) == limit {
+			res.Truncated = true
+			break
+		}
+		values := make([]any, len(res.Columns))
+		ptrs := make([]any, len(values))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]any, len(values))
+		for i, col := range res.Columns {
+			if b, ok := values[i].([]byte); ok {
+				values[i] = string(b)
+			}
+			row[col] = values[i]
+		}
+		res.Rows = append(res.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "query failed: %v", err)
+	}
+	res.RowCount = len(res.Rows)
+	rows.Close()
+
+	if !readOnly {
+		if err := tx.Commit(); err != nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "commit failed: %v", err)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,194 @@
+package skill
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	pb "yafai-skill/proto"
+
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestParseStatement(t *testing.T) {
+	tests := []struct {
+		query    string
+		params   []string
+		sqlite   string
+		postgres string
+	}{
+		{
+			query:    "SELECT * FROM items WHERE owner = :owner OR name = :owner AND id > :id",
+			params:   []string{"owner", "id"},
+			sqlite:   "SELECT * FROM items WHERE owner = ?1 OR name = ?1 AND id > ?2",
+			postgres: "SELECT * FROM items WHERE owner = $1 OR name = $1 AND id > $2",
+		},
+		{
+			query:    "SELECT :since::date, total::text FROM orders",
+			params:   []string{"since"},
+			sqlite:   "SELECT ?1::date, total::text FROM orders",
+			postgres: "SELECT $1::date, total::text FROM orders",
+		},
+		{
+			query:    "SELECT ':quoted', \":ident\" -- :comment\n/* :block */ FROM t WHERE a = :a",
+			params:   []string{"a"},
+			sqlite:   "SELECT ':quoted', \":ident\" -- :comment\n/* :block */ FROM t WHERE a = ?1",
+			postgres: "SELECT ':quoted', \":ident\" -- :comment\n/* :block */ FROM t WHERE a = $1",
+		},
+	}
+	for _, tt := range tests {
+		st, err := parseStatement(tt.query)
+		if err != nil {
+			t.Fatalf("parseStatement(%q): %v", tt.query, err)
+		}
+		if !slices.Equal(st.params, tt.params) {
+			t.Errorf("parseStatement(%q) params = %v, want %v", tt.query, st.params, tt.params)
+		}
+		if got := st.text(DriverSQLite); got != tt.sqlite {
+			t.Errorf("sqlite text of %q = %q, want %q", tt.query, got, tt.sqlite)
+		}
+		if got := st.text(DriverPostgres); got != tt.postgres {
+			t.Errorf("postgres text of %q = %q, want %q", tt.query, got, tt.postgres)
+		}
+	}
+}
+
+func TestParseStatementRejectsDriverPlaceholders(t *testing.T) {
+	for _, query := range []string{
+		"SELECT * FROM items WHERE id = ?",
+		"SELECT * FROM items WHERE id = $1",
+	} {
+		if _, err := parseStatement(query); err == nil {
+			t.Errorf("parseStatement(%q) succeeded, want an error", query)
+		}
+	}
+}
+
+// newSQLServer creates a SQLite database with five items owned by alice and
+// bob, and a server with it configured as "test".
+func newSQLServer(t *testing.T) (*SkillServer, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, stmt := range []string{
+		"CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT, owner TEXT)",
+		"INSERT INTO items (name, owner) VALUES ('a', 'alice'), ('b', 'alice'), ('c', 'bob'), ('d', 'bob'), ('e', 'bob')",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dbs, err := NewDatabases(map[string]*DatabaseConfig{"test": {Driver: DriverSQLite, DSN: path}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dbs.Close() })
+	return &SkillServer{Databases: dbs}, path
+}
+
+// runSQL validates the action and runs it with the body params.
+func runSQL(t *testing.T, s *SkillServer, def *Action, params map[string]any) (*queryResult, error) {
+	t.Helper()
+	def.Type = ActionSQL
+	if err := def.Validate(nil); err != nil {
+		t.Fatalf("validating action: %v", err)
+	}
+	body, err := structpb.NewStruct(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := s.newRunningAction(&pb.ExecuteActionRequest{Name: "Query", BodyParams: body}, def)
+	if err != nil {
+		t.Fatalf("newRunningAction: %v", err)
+	}
+	return a.query(context.Background())
+}
+
+func TestSQLQueryBindsNamedParams(t *testing.T) {
+	s, _ := newSQLServer(t)
+	def := &Action{
+		Params: []*Param{{Name: "who", In: "body"}},
+		SQL:    &SQLSpec{Database: "test", Query: "SELECT name FROM items WHERE owner = :who OR name = :who ORDER BY id"},
+	}
+	res, err := runSQL(t, s, def, map[string]any{"who": "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []any
+	for _, row := range res.Rows {
+		names = append(names, row["name"])
+	}
+	if want := []any{"a", "b"}; !slices.Equal(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+}
+
+func TestSQLQueryTruncatesAtMaxRows(t *testing.T) {
+	s, _ := newSQLServer(t)
+	def := &Action{SQL: &SQLSpec{Database: "test", Query: "SELECT id FROM items ORDER BY id", MaxRows: 2}}
+	res, err := runSQL(t, s, def, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.RowCount != 2 || len(res.Rows) != 2 || !res.Truncated {
+		t.Errorf("got %d rows (row_count %d, truncated %v), want 2 truncated", len(res.Rows), res.RowCount, res.Truncated)
+	}
+}
+
+func TestSQLQueryReadOnlyRejectsWrites(t *testing.T) {
+	s, path := newSQLServer(t)
+	def := &Action{
+		Params: []*Param{{Name: "name", In: "body"}},
+		SQL:    &SQLSpec{Database: "test", Query: "INSERT INTO items (name, owner) VALUES (:name, 'mallory') RETURNING id"},
+	}
+	if _, err := runSQL(t, s, def, map[string]any{"name": "x"}); err == nil {
+		t.Fatal("insert under read_only succeeded, want an error")
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM items WHERE owner = 'mallory'").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("read-only insert wrote %d rows", count)
+	}
+}
+
+func TestDatabasesPoolIsSharedAcrossConcurrentCalls(t *testing.T) {
+	s, _ := newSQLServer(t)
+	pools := make([]*sql.DB, 8)
+	var wg sync.WaitGroup
+	for i := range pools {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			db, _, err := s.Databases.pool(context.Background(), nil, "test", true)
+			if err != nil {
+				t.Error(err)
+			}
+			pools[i] = db
+		}()
+	}
+	wg.Wait()
+	for _, db := range pools[1:] {
+		if db != pools[0] {
+			t.Fatal("concurrent calls opened more than one pool")
+		}
+	}
+	if n := len(s.Databases.pools); n != 1 {
+		t.Errorf("%d pools kept, want 1", n)
+	}
+}
@@ -0,0 +1,34 @@
+package skill
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer uses the global provider, so spans are dropped until tracing is set up.
+var tracer = otel.Tracer("yafai-skill/handler")
+
+func actionAttr(name string) attribute.KeyValue {
+	return attribute.String("skill.action", name)
+}
+
+// endSpan marks the span failed when err is set and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	span.End()
+}
+
+// templateFailed records a response template that failed to parse or execute.
+func (s *SkillServer) templateFailed(ctx context.Context, action, template string, err error) {
+	s.Metrics.templateFailure(action, template)
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err, trace.WithAttributes(attribute.String("skill.template", template)))
+	span.SetStatus(otelcodes.Error, "template "+template+" failed")
+}
@@ -0,0 +1,194 @@
+package skill
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"text/template"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RequestStage reshapes the agent's arguments into the upstream's params:
+//
+//	request:
+//	  transform:
+//	    body:
+//	      filterGroups:
+//	        - filters: [{propertyName: email, operator: EQ, value: "{{ .email }}"}]
+//
+// Each of path, query and body that is given replaces those params of the
+// call. Strings are templates over the call's arguments, and a string that is
+// a single {{ }} expression keeps the type of its value.
+type RequestStage struct {
+	Transform *RequestTransform `yaml:"transform"`
+}
+
+// RequestTransform gives the params a call sends upstream.
+type RequestTransform struct {
+	Path  map[string]any `yaml:"path"`
+	Query map[string]any `yaml:"query"`
+	Body  any            `yaml:"body"` // An object becomes the body params, anything else the raw body
+}
+
+// ResponseStage reshapes a successful result before the response template
+// and later workflow steps see it:
+//
+//	response:
+//	  transform: "{{ flatten .results \"properties\" }}"
+type ResponseStage struct {
+	Transform any `yaml:"transform"` // Rendered like request transforms, over the decoded result
+}
+
+func (r *RequestStage) validate() error {
+	if r.Transform == nil {
+		return nil
+	}
+	for _, v := range []any{r.Transform.Path, r.Transform.Query, r.Transform.Body} {
+		if err := checkTemplates(v); err != nil {
+			return fmt.Errorf("request transform: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *ResponseStage) validate() error {
+	if err := checkTemplates(r.Transform); err != nil {
+		return fmt.Errorf("response transform: %w", err)
+	}
+	return nil
+}
+
+// transformRequest replaces the call's params with the rendered request
+// transform.
+func (a *RunningAction) transformRequest(t *RequestTransform) error {
+	args := a.arguments()
+	if t.Path != nil {
+		path, err := renderValue(t.Path, args)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "request transform: %v", err)
+		}
+		a.PathParams = path.(map[string]any)
+	}
+	if t.Query != nil {
+		query, err := renderValue(t.Query, args)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "request transform: %v", err)
+		}
+		a.QueryParams = query.(map[string]any)
+	}
+	if t.Body != nil {
+		body, err := renderValue(t.Body, args)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "request transform: %v", err)
+		}
+		if m, ok := body.(map[string]any); ok {
+			a.BodyParams, a.RawBody = m, nil
+		} else {
+			a.BodyParams, a.RawBody = map[string]any{}, body
+		}
+	}
+	return nil
+}
+
+// transformResponse applies the response transform to a successful result.
+func (a *RunningAction) transformResponse(res ActionResult) ActionResult {
+	if a.Response == nil || a.Response.Transform == nil || (res.Error != nil && !res.partial()) {
+		return res
+	}
+	data, ok := stepResult(res.Result).(map[string]any)
+	if !ok {
+		data = map[string]any{"result": stepResult(res.Result)}
+	}
+	out, err := renderValue(a.Response.Transform, data)
+	if err == nil {
+		var b []byte
+		if b, err = json.Marshal(out); err == nil {
+			res.Result = string(b)
+			return res
+		}
+	}
+	res.Error = status.Errorf(codes.Internal, "response transform: %v", err)
+	return res
+}
+
+// valueFuncs are the functions available to templates rendering values, for
+// reshaping objects and lists of objects.
+var valueFuncs = template.FuncMap{
+	// pick keeps the given keys of an object, or of each object in a list
+	"pick": func(v any, keys ...string) any {
+		return eachObject(v, func(m map[string]any) map[string]any {
+			out := make(map[string]any, len(keys))
+			for _, k := range keys {
+				if item, ok := m[k]; ok {
+					out[k] = item
+				}
+			}
+			return out
+		})
+	},
+	// omit drops the given keys
+	"omit": func(v any, keys ...string) any {
+		return eachObject(v, func(m map[string]any) map[string]any {
+			out := maps.Clone(m)
+			for _, k := range keys {
+				delete(out, k)
+			}
+			return out
+		})
+	},
+	// flatten moves the fields of the object under key up into its parent
+	"flatten": func(v any, key string) any {
+		return eachObject(v, func(m map[string]any) map[string]any {
+			out := maps.Clone(m)
+			nested, _ := out[key].(map[string]any)
+			delete(out, key)
+			for k, item := range nested {
+				if _, ok := out[k]; !ok {
+					out[k] = item
+				}
+			}
+			return out
+		})
+	},
+	// merge combines objects, later ones winning
+	"merge": func(objects ...map[string]any) map[string]any {
+		out := make(map[string]any)
+		for _, m := range objects {
+			maps.Copy(out, m)
+		}
+		return out
+	},
+	// pluck lists the value of key in each object of a list
+	"pluck": func(list []any, key string) []any {
+		out := make([]any, 0, len(list))
+		for _, item := range list {
+			if m, ok := item.(map[string]any); ok {
+				out = append(out, m[key])
+			}
+		}
+		return out
+	},
+}
+
+// eachObject applies fn to v if it is an object, or to each object in v if it
+// is a list. Other values are returned as they are.
+func eachObject(v any, fn func(map[string]any) map[string]any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		return fn(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			if m, ok := item.(map[string]any); ok {
+				out[i] = fn(m)
+			} else {
+				out[i] = item
+			}
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -0,0 +1,241 @@
+package skill
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultActionTimeout bounds a call when the action sets no timeout.
+const DefaultActionTimeout = 15 * time.Second
+
+// errReadTimeout cancels a request whose response did not arrive within the action's read_timeout.
+var errReadTimeout = errors.New("read timeout waiting for the upstream response")
+
+// defaultTransport is shared by calls when no transport is configured.
+var defaultTransport, _ = NewTransport(HTTPConfig{}, defaultNetGuard)
+
+// HTTPConfig is the manifest's http section, tuning the transport shared by all actions.
+type HTTPConfig struct {
+	MaxIdleConns        int    `yaml:"max_idle_conns"`
+	MaxIdleConnsPerHost int    `yaml:"max_idle_conns_per_host"`
+	MaxConnsPerHost     int    `yaml:"max_conns_per_host"`
+	IdleConnTimeout     string `yaml:"idle_conn_timeout"` // How long idle connections are kept for reuse
+	KeepAlive           string `yaml:"keep_alive"`        // TCP keep-alive period
+	TLSHandshakeTimeout string `yaml:"tls_handshake_timeout"`
+	DisableHTTP2        bool   `yaml:"disable_http2"`
+	Proxy               string `yaml:"proxy"`       // Proxy URL, "environment" for HTTPS_PROXY/NO_PROXY, or "none" (default)
+	CAFile              string `yaml:"ca_file"`     // PEM bundle trusted in addition to the system roots
+	ClientCert          string `yaml:"client_cert"` // PEM certificate presented for mTLS
+	ClientKey           string `yaml:"client_key"`
+}
+
+// NewTransport builds the HTTP transport shared by all actions. Direct
+// connections go through the guard's address checks. When a proxy is used the
+// dialer only sees the proxy, so the guard is set to resolve and check each
+// request's host before it is sent.
+func NewTransport(cfg HTTPConfig, guard *NetGuard) (*http.Transport, error) {
+	keepAlive, err := parseDuration("keep_alive", cfg.KeepAlive, 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	idle, err := parseDuration("idle_conn_timeout", cfg.IdleConnTimeout, 90*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	handshake, err := parseDuration("tls_handshake_timeout", cfg.TLSHandshakeTimeout, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &http.Transport{
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		IdleConnTimeout:       idle,
+		TLSHandshakeTimeout:   handshake,
+		ExpectContinueTimeout: time.Second,
+		ForceAttemptHTTP2:     !cfg.DisableHTTP2,
+	}
+	if cfg.MaxIdleConns > 0 {
+		t.MaxIdleConns = cfg.MaxIdleConns
+	}
+	if cfg.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+	if cfg.DisableHTTP2 {
+		// A non-nil empty map turns off the transport's HTTP/2 upgrade
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	var proxyAddr string
+	var envProxy bool // Only a transport using the environment's proxy may dial it unguarded
+	switch cfg.Proxy {
+	case "", "none":
+	case "environment":
+		t.Proxy = http.ProxyFromEnvironment
+		envProxy = true
+		guard.ResolveHosts = true
+	default:
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy %q", cfg.Proxy)
+		}
+		t.Proxy = http.ProxyURL(proxyURL)
+		proxyAddr = canonicalAddr(proxyURL)
+		guard.ResolveHosts = true
+	}
+
+	guarded := &net.Dialer{KeepAlive: keepAlive, Control: guard.Control}
+	direct := &net.Dialer{KeepAlive: keepAlive}
+	t.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if d, ok := ctx.Value(connectTimeoutKey{}).(time.Duration); ok && d > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancel()
+		}
+		if (proxyAddr != "" && addr == proxyAddr) || (envProxy && isEnvProxy(addr)) {
+			return direct.DialContext(ctx, network, addr)
+		}
+		return guarded.DialContext(ctx, network, addr)
+	}
+
+	tlsConfig, err := clientTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	t.TLSClientConfig = tlsConfig
+	return t, nil
+}
+
+// clientTLSConfig loads the extra CA bundle and client certificate.
+func clientTLSConfig(cfg HTTPConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading ca_file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca_file %s holds no PEM certificates", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.ClientCert != "" || cfg.ClientKey != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// isEnvProxy reports whether addr is the proxy configured in the environment.
+func isEnvProxy(addr string) bool {
+	for _, scheme := range []string{"https", "http"} {
+		req := &http.Request{URL: &url.URL{Scheme: scheme, Host: "example.com"}}
+		if u, err := http.ProxyFromEnvironment(req); err == nil && u != nil && canonicalAddr(u) == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func canonicalAddr(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+func parseDuration(name, value string, def time.Duration) (time.Duration, error) {
+	if value == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", name, value, err)
+	}
+	return d, nil
+}
+
+// ActionTimeouts are the per-action limits on a call.
+type ActionTimeouts struct {
+	Total   time.Duration // Whole call including retries of redirects and reading the body
+	Connect time.Duration // Establishing a new connection
+	Read    time.Duration // Waiting for the response once the request is written
+}
+
+// Timeouts parses the action's timeout settings.
+func (a *Action) Timeouts() (ActionTimeouts, error) {
+	var t ActionTimeouts
+	var err error
+	if t.Total, err = parseDuration("timeout", a.Timeout, DefaultActionTimeout); err != nil {
+		return t, err
+	}
+	if t.Connect, err = parseDuration("connect_timeout", a.ConnectTimeout, 0); err != nil {
+		return t, err
+	}
+	if t.Read, err = parseDuration("read_timeout", a.ReadTimeout, 0); err != nil {
+		return t, err
+	}
+	return t, nil
+}
+
+type connectTimeoutKey struct{}
+
+// withTimeouts applies the action's timeouts to the request context. The
+// caller's own deadline still applies when it is earlier. The returned
+// function must be called once the response body has been read.
+func withTimeouts(ctx context.Context, t ActionTimeouts) (context.Context, func()) {
+	ctx, cancelTotal := context.WithTimeout(ctx, t.Total)
+	if t.Connect > 0 {
+		ctx = context.WithValue(ctx, connectTimeoutKey{}, t.Connect)
+	}
+	if t.Read <= 0 {
+		return ctx, cancelTotal
+	}
+
+	ctx, cancelRead := context.WithCancelCause(ctx)
+	var mu sync.Mutex
+	var timer *time.Timer
+	trace := &httptrace.ClientTrace{
+		// Restarted for every redirect hop
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.AfterFunc(t.Read, func() { cancelRead(errReadTimeout) })
+		},
+	}
+	ctx = httptrace.WithClientTrace(ctx, trace)
+	return ctx, func() {
+		mu.Lock()
+		if timer != nil {
+			timer.Stop()
+		}
+		mu.Unlock()
+		cancelRead(nil)
+		cancelTotal()
+	}
+}
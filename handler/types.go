@@ -1,7 +1,11 @@
 package skill
 
 import (
+	"net/http"
+
 	skill "yafai-skill/proto"
+	"yafai-skill/redact"
+	"yafai-skill/secrets"
 )
 
 // APISpec represents the top-level structure of your YAML file.
@@ -9,6 +13,53 @@ type APISpec struct {
 	Name        string             `yaml:"name"`
 	Description string             `yaml:"description"`
 	Actions     map[string]*Action `yaml:"actions"`
+	Confirm     string             `yaml:"confirm"`      // Default approval policy for actions: "required", "never" or "auto"
+	ApprovalTTL string             `yaml:"approval_ttl"` // How long approval tickets stay valid (e.g. "15m")
+
+	AllowedHosts    []string `yaml:"allowed_hosts"`    // Hosts actions may call; "*.example.com" matches subdomains
+	AllowedNetworks []string `yaml:"allowed_networks"` // CIDRs exempt from private/loopback/link-local blocking
+
+	AuthToken string                   `yaml:"auth_token"` // Bearer token reference, e.g. "secret://hubspot_token"
+	Secrets   map[string]*secrets.Spec `yaml:"secrets"`    // Where each referenced secret is resolved from
+
+	Redact redact.Config `yaml:"redact"` // Fields and patterns masked in logs
+
+	CacheStore CacheStoreConfig `yaml:"cache_store"` // Size limits and disk location of the response cache
+
+	RateLimit RateLimitConfig `yaml:"rate_limit"` // Client-side limits for calls to the upstream API
+
+	CircuitBreaker *BreakerConfig `yaml:"circuit_breaker"` // Fail fast while an upstream keeps failing
+
+	HTTP HTTPConfig `yaml:"http"` // Connection pooling, proxy and TLS settings for upstream calls
+
+	Readiness *ReadinessProbe `yaml:"readiness"` // Periodic upstream check behind the gRPC health status
+
+	Batch BatchConfig `yaml:"batch"` // Size and parallelism limits of BatchExecuteActions
+
+	Jobs JobsConfig `yaml:"jobs"` // Storage and completion webhook of background jobs
+
+	Databases map[string]*DatabaseConfig `yaml:"databases"` // Connections sql actions query, by name
+}
+
+// SensitiveFields lists the param names marked sensitive and the response
+// fields actions declare as sensitive.
+func (spec *APISpec) SensitiveFields() []string {
+	var fields []string
+	var walk func(params []*Param)
+	walk = func(params []*Param) {
+		for _, p := range params {
+			if p.Sensitive {
+				fields = append(fields, p.Name)
+			}
+			walk(p.Properties)
+			walk(p.Items)
+		}
+	}
+	for _, action := range spec.Actions {
+		walk(action.Params)
+		fields = append(fields, action.SensitiveFields...)
+	}
+	return fields
 }
 
 type SkillServer struct {
@@ -16,7 +67,23 @@ type SkillServer struct {
 	Description                           string
 	skill.UnimplementedSkillServiceServer                    // Embed the generated gRPC server interface          // Holds the parsed API specification from the YAML
 	ActionsMap                            map[string]*Action // Optional: For quicker lookup of actions by name
-
+	Confirm                               string             // Manifest-level approval policy applied when an action sets none
+	Approvals                             *ApprovalQueue     // Calls waiting for a human decision
+	Approvers                             []string           // Caller patterns allowed to approve and reject calls
+	Policy                                *Policy            // Authorization policy, nil when none is configured
+	Guard                                 *NetGuard          // Outbound host and address restrictions
+	Transport                             *http.Transport    // Shared connection pool for upstream calls
+	Cache                                 *ResponseCache     // Cached upstream responses for actions with a cache policy
+	Limits                                *RateLimiter       // Rate and concurrency limits for upstream calls
+	Breakers                              *CircuitBreakers   // Per-host or per-action circuit breakers
+	Secrets                               *secrets.Resolver  // Resolves secret:// references in headers and auth
+	AuthToken                             string             // Bearer token reference; defaults to the skill key
+	Metrics                               *Metrics           // Prometheus metrics; nil disables them
+	Audit                                 *AuditLog          // Append-only record of executed actions; nil disables it
+	Redactor                              *redact.Redactor   // Masks credentials in the headers held calls show; nil shows none
+	Batch                                 BatchConfig        // Limits of BatchExecuteActions
+	Jobs                                  *JobStore          // Background jobs started with async calls
+	Databases                             *Databases         // Connection pools of sql actions
 }
 
 // Action represents a single API action.
@@ -31,17 +98,37 @@ type Param struct {
 	Enum       []string `yaml:"enum"`
 	Properties []*Param `yaml:"properties,omitempty"` // For nested objects (recursive)
 	Items      []*Param `yaml:"items,omitempty"`      // For array of objects (recursive)
+	Sensitive  bool     `yaml:"sensitive,omitempty"`  // Masked wherever the value is logged
 }
 
 // Action represents an API action (e.g., CreateDeal)
 type Action struct {
 	Name             string            `yaml:"name"`
 	Desc             string            `yaml:"desc"`
+	Type             string            `yaml:"type"` // "http" (default), "graphql", "grpc", "sql", "wasm", "workflow" or "exec"
 	BaseURL          string            `yaml:"base_url"`
 	Method           string            `yaml:"method"`
 	Params           []*Param          `yaml:"params"`
 	Headers          map[string]string `yaml:"headers"`
 	ResponseTemplate ResponseTemplate  `yaml:"response_template"`
+	Confirm          string            `yaml:"confirm"`          // Approval policy: "never" (default), "required" or "auto" (held unless GET)
+	FollowRedirects  *bool             `yaml:"follow_redirects"` // Defaults to true; false returns the redirect response as is
+	SensitiveFields  []string          `yaml:"sensitive_fields"` // Response fields masked wherever they are logged
+	Cache            *CachePolicy      `yaml:"cache"`            // Opt-in response caching for idempotent calls
+	Async            *AsyncPolicy      `yaml:"async"`            // Long-running upstream work answered with 202 Accepted
+	RateLimit        *RateLimitConfig  `yaml:"rate_limit"`       // Per-action limits applied on top of the skill's
+	Timeout          string            `yaml:"timeout"`          // Whole call, defaults to 15s
+	ConnectTimeout   string            `yaml:"connect_timeout"`  // Establishing a new connection
+	ReadTimeout      string            `yaml:"read_timeout"`     // Waiting for the response once the request is sent
+	Steps            []*WorkflowStep   `yaml:"steps"`            // Workflow actions only: the actions to call, in order
+	Exec             *ExecSpec         `yaml:"exec"`             // Exec actions only: the program to run
+	GraphQL          *GraphQLSpec      `yaml:"graphql"`          // GraphQL actions only: the operation to send
+	GRPC             *GRPCSpec         `yaml:"grpc"`             // gRPC actions only: the upstream method to call
+	SQL              *SQLSpec          `yaml:"sql"`              // SQL actions only: the query to run
+	Wasm             *WasmSpec         `yaml:"wasm"`             // Wasm actions only: the module to run
+	Hooks            *Hooks            `yaml:"hooks"`            // HTTP actions only: modules run before the request and after the response
+	Request          *RequestStage     `yaml:"request"`          // Maps the agent's arguments to the upstream's params
+	Response         *ResponseStage    `yaml:"response"`         // Reshapes the result before templating
 }
 
 // ResponseTemplate is the response structure for success and failure messages
@@ -54,6 +141,17 @@ type ResponseTemplate struct {
 type RunningAction struct {
 	Name             string
 	Desc             string
+	Type             string
+	Steps            []*WorkflowStep
+	Exec             *ExecSpec
+	Args             []string // Rendered program arguments of exec actions
+	GraphQL          *GraphQLSpec
+	GRPC             *GRPCSpec
+	SQL              *SQLSpec
+	Databases        *Databases
+	Wasm             *WasmSpec
+	Hooks            *Hooks
+	Response         *ResponseStage
 	BaseURL          string
 	Method           string
 	Headers          map[string]string
@@ -63,9 +161,31 @@ type RunningAction struct {
 	RawBody          interface{}
 	Body             string           // For cases where the body needs to be a raw string (e.g., non-JSON)
 	ResponseTemplate ResponseTemplate `yaml:"response_template"`
+	Guard            *NetGuard
+	Transport        *http.Transport
+	Timeouts         ActionTimeouts
+	FollowRedirects  bool
+	Secrets          *secrets.Resolver
+	AuthToken        string
+	NoCredentials    bool // Send no bearer token, for polls of another origin
+	Cache            *CachePolicy
+	Async            *AsyncPolicy
+	IfNoneMatch      string // Conditional request headers when revalidating a cached response
+	IfModifiedSince  string
+	Metrics          *Metrics
+	StatusCode       int // Upstream status of the last execution, for the audit log
 }
 
 type ActionResult struct {
-	Result string
-	Error  error
+	Result     string
+	Error      error
+	StatusCode int
+	Header     http.Header
+	Reported   bool // Error goes in the response's error field instead of failing the call
+}
+
+// partial reports whether a reported error came with data, which templates
+// still see.
+func (r ActionResult) partial() bool {
+	return r.Error != nil && r.Reported && r.Result != ""
 }
@@ -0,0 +1,251 @@
+package skill
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	pb "yafai-skill/proto"
+	"yafai-skill/secrets"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ActionWasm runs a WebAssembly module instead of calling an API.
+const ActionWasm = "wasm"
+
+// Defaults for WebAssembly modules.
+const (
+	DefaultWasmMaxMemory = 64 // MiB
+	DefaultWasmTimeout   = 5 * time.Second
+	wasmPageSize         = 64 << 10
+)
+
+// WasmSpec is a WASI module run by a wasm action or a hook:
+//
+//	wasm:
+//	  module: plugins/score.wasm
+//	  env_values: {API_SECRET: secret://score_secret}
+//	  max_memory: 32
+//
+// The module is a WASI command. It reads its input as JSON on stdin and
+// writes its output as JSON to stdout; anything on stderr is logged, and a
+// non-zero exit status fails the call. It has no access to files or the
+// network.
+type WasmSpec struct {
+	Module    string            `yaml:"module"`     // Path of the .wasm file
+	EnvValues map[string]string `yaml:"env_values"` // The module's environment; secret:// references allowed
+	MaxMemory int               `yaml:"max_memory"` // MiB of memory the module may use, defaults to 64
+	MaxOutput int64             `yaml:"max_output"` // Bytes of stdout allowed, defaults to 1 MiB
+	Timeout   string            `yaml:"timeout"`    // Longest a run may take, defaults to 5s
+	ExitCodes map[int]string    `yaml:"exit_codes"` // Exit status to error code, e.g. 2: INVALID_ARGUMENT; others are UNKNOWN
+
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+}
+
+// Hooks are WebAssembly modules run around the request of an HTTP action.
+type Hooks struct {
+	PreRequest   *WasmSpec `yaml:"pre_request"`   // Gets the request and returns it modified, e.g. signed
+	PostResponse *WasmSpec `yaml:"post_response"` // Gets the response and returns the result templates see
+}
+
+func (h *Hooks) validate() error {
+	if h.PreRequest != nil {
+		if err := h.PreRequest.validate(); err != nil {
+			return fmt.Errorf("pre_request hook: %w", err)
+		}
+	}
+	if h.PostResponse != nil {
+		if err := h.PostResponse.validate(); err != nil {
+			return fmt.Errorf("post_response hook: %w", err)
+		}
+	}
+	return nil
+}
+
+// validate compiles the module. Each module gets its own runtime, which is
+// where wazero enforces the memory limit.
+func (w *WasmSpec) validate() error {
+	if w == nil || w.Module == "" {
+		return fmt.Errorf("wasm needs a module")
+	}
+	if _, err := parseDuration("timeout", w.Timeout, DefaultWasmTimeout); err != nil {
+		return err
+	}
+	for code, name := range w.ExitCodes {
+		if _, ok := pb.ErrorCode_value[name]; !ok {
+			return fmt.Errorf("wasm exit code %d: unknown error code %q", code, name)
+		}
+	}
+	if w.compiled != nil {
+		return nil
+	}
+	code, err := os.ReadFile(w.Module)
+	if err != nil {
+		return fmt.Errorf("reading wasm module: %w", err)
+	}
+
+	ctx := context.Background()
+	memory := w.MaxMemory
+	if memory <= 0 {
+		memory = DefaultWasmMaxMemory
+	}
+	w.runtime = wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(uint32(memory<<20/wasmPageSize)).
+		WithCloseOnContextDone(true))
+	wasi_snapshot_preview1.MustInstantiate(ctx, w.runtime)
+	if w.compiled, err = w.runtime.CompileModule(ctx, code); err != nil {
+		return fmt.Errorf("wasm module %s: %w", w.Module, err)
+	}
+	return nil
+}
+
+// run executes the module with input as JSON on stdin and returns its JSON
+// output.
+func (w *WasmSpec) run(ctx context.Context, resolver *secrets.Resolver, input any) (json.RawMessage, error) {
+	timeout, _ := parseDuration("timeout", w.Timeout, DefaultWasmTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	in, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	limit := w.MaxOutput
+	if limit <= 0 {
+		limit = DefaultExecMaxOutput
+	}
+	stdout := &cappedBuffer{limit: limit, overflow: cancel}
+	stderr := &cappedBuffer{limit: execMaxStderr}
+
+	config := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs(filepath.Base(w.Module)).
+		WithStdin(bytes.NewReader(in)).
+		WithStdout(stdout).
+		WithStderr(stderr).
+		WithSysWalltime().
+		WithSysNanotime().
+		WithRandSource(rand.Reader)
+	for name, value := range w.EnvValues {
+		v, err := resolver.Expand(ctx, value)
+		if err != nil {
+			return nil, err
+		}
+		config = config.WithEnv(name, v)
+	}
+
+	mod, err := w.runtime.InstantiateModule(ctx, w.compiled, config)
+	if mod != nil {
+		mod.Close(context.Background())
+	}
+	if msg := strings.TrimSpace(stderr.buf.String()); msg != "" {
+		slog.DebugContext(ctx, "Wasm module stderr", "module", w.Module, "stderr", msg)
+	}
+
+	var exitErr *sys.ExitError
+	switch {
+	case stdout.exceeded:
+		return nil, status.Errorf(codes.ResourceExhausted, "wasm output exceeded %d bytes", limit)
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return nil, status.Errorf(codes.DeadlineExceeded, "wasm module timed out after %s", timeout)
+	case errors.As(err, &exitErr):
+		code := codes.Unknown
+		if name, ok := w.ExitCodes[int(exitErr.ExitCode())]; ok {
+			code = codes.Code(pb.ErrorCode_value[name])
+		}
+		// Runtimes print a stack trace after the message, keep the message
+		msg, _, _ := strings.Cut(strings.TrimSpace(stderr.buf.String()), "\n")
+		return nil, status.Errorf(code, "wasm module exited with status %d: %s", exitErr.ExitCode(), msg)
+	case err != nil:
+		return nil, status.Errorf(codes.Internal, "wasm module failed: %v", err)
+	}
+
+	out := bytes.TrimSpace(stdout.buf.Bytes())
+	if !json.Valid(out) {
+		return nil, status.Error(codes.Internal, "wasm output is not JSON")
+	}
+	return out, nil
+}
+
+// runWasm is Execute for wasm actions. The module gets
+// {"action": name, "args": {...}} and its output is the result.
+func (a *RunningAction) runWasm(ctx context.Context, resultChan chan<- ActionResult) {
+	ctx, cancel := context.WithTimeout(ctx, a.Timeouts.Total)
+	defer cancel()
+	out, err := a.Wasm.run(ctx, a.Secrets, map[string]any{"action": a.Name, "args": a.arguments()})
+	if err != nil {
+		resultChan <- ActionResult{Error: err}
+		return
+	}
+	resultChan <- ActionResult{Result: string(out)}
+}
+
+// hookRequest is what a pre_request hook gets and returns. Fields it leaves
+// out of its output keep their value.
+type hookRequest struct {
+	Action  string            `json:"action"`
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+// preRequest runs the pre_request hook on req and returns the request to send.
+func (a *RunningAction) preRequest(ctx context.Context, req *http.Request, body []byte) (*http.Request, error) {
+	in := hookRequest{Action: a.Name, Method: req.Method, URL: req.URL.String(), Headers: map[string]string{}, Body: string(body)}
+	for key := range req.Header {
+		in.Headers[key] = req.Header.Get(key)
+	}
+	raw, err := a.Hooks.PreRequest.run(ctx, a.Secrets, in)
+	if err != nil {
+		return nil, err
+	}
+	out := in
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, status.Errorf(codes.Internal, "pre_request hook output: %v", err)
+	}
+
+	var payload io.Reader
+	if out.Body != "" {
+		payload = strings.NewReader(out.Body)
+	}
+	modified, err := http.NewRequestWithContext(ctx, out.Method, out.URL, payload)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "pre_request hook output: %v", err)
+	}
+	for key, value := range out.Headers {
+		modified.Header.Set(key, value)
+	}
+	return modified, nil
+}
+
+// postResponse runs the post_response hook, which gets
+// {"action", "status", "headers", "body"} and returns the result.
+func (a *RunningAction) postResponse(ctx context.Context, resp *http.Response, body []byte) ([]byte, error) {
+	headers := make(map[string]string, len(resp.Header))
+	for key := range resp.Header {
+		headers[key] = resp.Header.Get(key)
+	}
+	return a.Hooks.PostResponse.run(ctx, a.Secrets, map[string]any{
+		"action":  a.Name,
+		"status":  resp.StatusCode,
+		"headers": headers,
+		"body":    string(body),
+	})
+}
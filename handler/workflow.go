@@ -0,0 +1,520 @@
+package skill
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	pb "yafai-skill/proto"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Action types accepted by the manifest "type" key.
+const (
+	ActionHTTP     = "http" // The default: one call to the upstream API
+	ActionWorkflow = "workflow"
+)
+
+// Step error handling accepted by the "on_error" key.
+const (
+	OnErrorStop       = "stop"
+	OnErrorContinue   = "continue"
+	OnErrorCompensate = "compensate"
+)
+
+// WorkflowStep calls another action of the skill as part of a workflow.
+// Parameter values are text/template strings over the workflow data:
+//
+//	.inputs   the workflow's own arguments
+//	.steps    the decoded results of earlier steps, by step name
+//	.errors   the errors of earlier steps that failed with on_error: continue
+//
+// A value that is a single {{ }} expression keeps the type of its result,
+// so numbers, lists and objects can be passed between steps.
+type WorkflowStep struct {
+	Name        string         `yaml:"name"`   // Key of the step's result in .steps, defaults to the action name
+	Action      string         `yaml:"action"` // Action of the same skill; workflows cannot be nested
+	PathParams  map[string]any `yaml:"path_params"`
+	QueryParams map[string]any `yaml:"query_params"`
+	BodyParams  map[string]any `yaml:"body_params"`
+	When        string         `yaml:"when"`       // Template; the step is skipped when it renders empty, false, 0 or no
+	OnError     string         `yaml:"on_error"`   // "stop" (default), "continue" or "compensate"
+	Compensate  *WorkflowStep  `yaml:"compensate"` // Undoes this step when a later step fails with on_error: compensate
+}
+
+func (step *WorkflowStep) key() string {
+	if step.Name != "" {
+		return step.Name
+	}
+	return step.Action
+}
+
+// Validate checks the action's type and async policy and, for workflows, that
+// every step names a non-workflow action of the skill and that the step
+// templates parse.
+func (a *Action) Validate(actions map[string]*Action) error {
+	if a.Async != nil {
+		if err := a.Async.validate(); err != nil {
+			return err
+		}
+	}
+	if a.Cache != nil {
+		if _, err := parseDuration("cache ttl", a.Cache.TTL, 0); err != nil {
+			return err
+		}
+	}
+	if len(a.Steps) > 0 && a.Type != ActionWorkflow {
+		return fmt.Errorf("steps are only allowed on workflow actions")
+	}
+	if a.Exec != nil && a.Type != ActionExec {
+		return fmt.Errorf("exec is only allowed on exec actions")
+	}
+	if a.GraphQL != nil && a.Type != ActionGraphQL {
+		return fmt.Errorf("graphql is only allowed on graphql actions")
+	}
+	if a.GRPC != nil && a.Type != ActionGRPC {
+		return fmt.Errorf("grpc is only allowed on grpc actions")
+	}
+	if a.SQL != nil && a.Type != ActionSQL {
+		return fmt.Errorf("sql is only allowed on sql actions")
+	}
+	if a.Wasm != nil && a.Type != ActionWasm {
+		return fmt.Errorf("wasm is only allowed on wasm actions")
+	}
+	if a.Hooks != nil && a.Type != "" && a.Type != ActionHTTP {
+		return fmt.Errorf("hooks are only allowed on http actions")
+	}
+	if a.Request != nil {
+		if a.Type == ActionWorkflow {
+			return fmt.Errorf("request transforms are not allowed on workflow actions, steps map their own params")
+		}
+		if err := a.Request.validate(); err != nil {
+			return err
+		}
+	}
+	if a.Response != nil {
+		if err := a.Response.validate(); err != nil {
+			return err
+		}
+	}
+	switch a.Type {
+	case "", ActionHTTP:
+		if a.Hooks != nil {
+			return a.Hooks.validate()
+		}
+		return nil
+	case ActionGraphQL:
+		if a.Method != "" && !strings.EqualFold(a.Method, http.MethodPost) {
+			return fmt.Errorf("graphql actions are sent with POST, not %s", a.Method)
+		}
+		a.Method = http.MethodPost
+		return a.GraphQL.validate(a.Params)
+	case ActionExec:
+		return a.Exec.validate()
+	case ActionGRPC:
+		return a.GRPC.validate(a.Headers)
+	case ActionSQL:
+		return a.SQL.validate(a.Params)
+	case ActionWasm:
+		return a.Wasm.validate()
+	case ActionWorkflow:
+	default:
+		return fmt.Errorf("unknown action type %q", a.Type)
+	}
+
+	if len(a.Steps) == 0 {
+		return fmt.Errorf("workflow has no steps")
+	}
+	seen := make(map[string]bool)
+	for i, step := range a.Steps {
+		if err := step.validate(actions); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+		if seen[step.key()] {
+			return fmt.Errorf("step %d: duplicate step name '%s'", i+1, step.key())
+		}
+		seen[step.key()] = true
+
+		switch step.OnError {
+		case "", OnErrorStop, OnErrorContinue, OnErrorCompensate:
+		default:
+			return fmt.Errorf("step %d: on_error must be stop, continue or compensate, got %q", i+1, step.OnError)
+		}
+		if c := step.Compensate; c != nil {
+			if c.Compensate != nil || c.OnError != "" {
+				return fmt.Errorf("step %d: compensation steps cannot set compensate or on_error", i+1)
+			}
+			if err := c.validate(actions); err != nil {
+				return fmt.Errorf("step %d compensation: %w", i+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (step *WorkflowStep) validate(actions map[string]*Action) error {
+	target, ok := actions[step.Action]
+	switch {
+	case step.Action == "":
+		return fmt.Errorf("no action given")
+	case !ok:
+		return fmt.Errorf("action '%s' not found", step.Action)
+	case target.Type == ActionWorkflow:
+		return fmt.Errorf("action '%s' is a workflow, workflows cannot be nested", step.Action)
+	}
+	if _, err := template.New("when").Funcs(valueFuncs).Parse(step.When); err != nil {
+		return fmt.Errorf("when: %w", err)
+	}
+	for _, params := range []map[string]any{step.PathParams, step.QueryParams, step.BodyParams} {
+		if err := checkTemplates(params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkTemplates parses every string in v as a template.
+func checkTemplates(v any) error {
+	switch v := v.(type) {
+	case string:
+		if _, err := template.New("value").Funcs(valueFuncs).Parse(v); err != nil {
+			return err
+		}
+	case map[string]any:
+		for _, item := range v {
+			if err := checkTemplates(item); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if err := checkTemplates(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// workflowRequiresApproval holds a workflow when its own policy requires it or
+// when any of its steps, compensations included, would be held on its own.
+// A workflow cannot be used to skip the approval of the actions it calls.
+func (s *SkillServer) workflowRequiresApproval(actionDef *Action) bool {
+	policy := actionDef.Confirm
+	if policy == "" {
+		policy = s.Confirm
+	}
+	if strings.EqualFold(policy, ConfirmRequired) {
+		return true
+	}
+	for _, step := range actionDef.Steps {
+		for _, st := range []*WorkflowStep{step, step.Compensate} {
+			if st == nil {
+				continue
+			}
+			if def, ok := s.ActionsMap[st.Action]; ok && s.requiresApproval(def) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stepActions lists the actions a workflow calls, for approvers.
+func (a *RunningAction) stepActions() string {
+	names := make([]string, len(a.Steps))
+	for i, step := range a.Steps {
+		names[i] = step.Action
+	}
+	return strings.Join(names, ", ")
+}
+
+// runWorkflow runs the steps of a workflow in order and renders the
+// workflow's response template over their results.
+func (s *SkillServer) runWorkflow(ctx context.Context, w *RunningAction) (*pb.ExecuteActionResponse, error) {
+	inputs := w.arguments()
+	results := make(map[string]any)
+	failures := make(map[string]any)
+	data := map[string]any{"inputs": inputs, "steps": results, "errors": failures}
+
+	var completed []*WorkflowStep // Steps that may need compensating, in order
+	for _, step := range w.Steps {
+		run, err := step.shouldRun(data)
+		if err == nil && !run {
+			slog.DebugContext(ctx, "Workflow step skipped", "workflow", w.Name, "step", step.key())
+			continue
+		}
+		var result any
+		if err == nil {
+			result, err = s.runStep(ctx, w.Name, step, data)
+		}
+		if err == nil {
+			results[step.key()] = result
+			completed = append(completed, step)
+			continue
+		}
+
+		failures[step.key()] = err.Error()
+		switch step.OnError {
+		case OnErrorContinue:
+			slog.WarnContext(ctx, "Workflow step failed, continuing", "workflow", w.Name, "step", step.key(), "error", err)
+			continue
+		case OnErrorCompensate:
+			data["compensated"] = s.compensate(ctx, w.Name, completed, data)
+		}
+		return s.renderWorkflow(ctx, w, data, fmt.Errorf("workflow step '%s' failed: %w", step.key(), err))
+	}
+	return s.renderWorkflow(ctx, w, data, nil)
+}
+
+// compensate undoes the completed steps in reverse order and returns the
+// names of those it undid. Compensation failures are logged and recorded in
+// .errors under "compensate:<step>"; the remaining steps are still undone.
+func (s *SkillServer) compensate(ctx context.Context, workflow string, completed []*WorkflowStep, data map[string]any) []string {
+	failures := data["errors"].(map[string]any)
+	var undone []string
+	for i := len(completed) - 1; i >= 0; i-- {
+		step := completed[i]
+		c := step.Compensate
+		if c == nil {
+			continue
+		}
+		run, err := c.shouldRun(data)
+		if err == nil && !run {
+			continue
+		}
+		if err == nil {
+			_, err = s.runStep(ctx, workflow, c, data)
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Workflow compensation failed", "workflow", workflow, "step", step.key(), "action", c.Action, "error", err)
+			failures["compensate:"+step.key()] = err.Error()
+			continue
+		}
+		undone = append(undone, step.key())
+	}
+	return undone
+}
+
+// runStep renders the step's arguments and executes its action through the
+// policy, cache, rate limits and circuit breakers. Each step gets its own
+// audit entry.
+func (s *SkillServer) runStep(ctx context.Context, workflow string, step *WorkflowStep, data map[string]any) (any, error) {
+	ctx, span := tracer.Start(ctx, "step "+step.key(), trace.WithAttributes(
+		actionAttr(step.Action), attribute.String("skill.workflow", workflow)))
+
+	start := time.Now()
+	req, err := step.request(data)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+	if s.Policy != nil {
+		// A grant of the workflow is not a grant of the actions it calls. The
+		// policy writes the refused step's audit entry.
+		if err := s.Policy.allowCall(ctx, CallerFromContext(ctx), s.Name, s.ActionsMap, req, workflow); err != nil {
+			endSpan(span, err)
+			return nil, err
+		}
+	}
+	var a *RunningAction
+	var res ActionResult
+	var cacheHit bool
+	a, err = s.newRunningAction(req, s.ActionsMap[step.Action])
+	if err == nil {
+		res, cacheHit, err = s.fetch(ctx, a)
+	}
+	if err == nil && a.Async != nil && res.StatusCode == http.StatusAccepted {
+		res, err = s.awaitAccepted(ctx, a, res)
+	}
+	if err == nil {
+		a.StatusCode = res.StatusCode
+		res = a.transformResponse(res)
+		err = res.Error
+	}
+	if s.Audit != nil {
+		args := auditArgs(structToMap(req.PathParams), structToMap(req.QueryParams), structToMap(req.BodyParams))
+		e := newAuditEntry(ctx, step.Action, args, a, &pb.ExecuteActionResponse{CacheHit: cacheHit}, err, start)
+		e.Workflow = workflow
+		s.Audit.write(ctx, e)
+	}
+	endSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return stepResult(res.Result), nil
+}
+
+// stepResult decodes a JSON response for use in later templates, keeping
+// other responses as {"result": body} like the response templates do.
+func stepResult(body string) any {
+	if unquoted, err := strconv.Unquote(body); err == nil {
+		body = unquoted
+	}
+	var v any
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		return map[string]any{"result": body}
+	}
+	return v
+}
+
+// request renders the step's parameters into a call of its action.
+func (step *WorkflowStep) request(data map[string]any) (*pb.ExecuteActionRequest, error) {
+	req := &pb.ExecuteActionRequest{Name: step.Action}
+	targets := []**structpb.Struct{&req.PathParams, &req.QueryParams, &req.BodyParams}
+	for i, params := range []map[string]any{step.PathParams, step.QueryParams, step.BodyParams} {
+		rendered, err := renderValue(params, data)
+		if err != nil {
+			return nil, fmt.Errorf("rendering arguments of '%s': %w", step.key(), err)
+		}
+		m, _ := rendered.(map[string]any)
+		if *targets[i], err = structpb.NewStruct(m); err != nil {
+			return nil, fmt.Errorf("arguments of '%s': %w", step.key(), err)
+		}
+	}
+	return req, nil
+}
+
+// shouldRun evaluates the step's condition.
+func (step *WorkflowStep) shouldRun(data map[string]any) (bool, error) {
+	if step.When == "" {
+		return true, nil
+	}
+	out, err := renderText(step.When, data)
+	if err != nil {
+		return false, fmt.Errorf("evaluating when of '%s': %w", step.key(), err)
+	}
+	switch strings.ToLower(strings.TrimSpace(out)) {
+	case "", "false", "0", "no", "<no value>":
+		return false, nil
+	}
+	return true, nil
+}
+
+// renderValue renders the strings in v, recursing into maps and lists.
+func renderValue(v any, data map[string]any) (any, error) {
+	switch v := v.(type) {
+	case string:
+		if expr, ok := soleExpression(v); ok {
+			return evalExpression(expr, data)
+		}
+		return renderText(v, data)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			rendered, err := renderValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = rendered
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			rendered, err := renderValue(item, data)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = rendered
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+func renderText(text string, data map[string]any) (string, error) {
+	tmpl, err := template.New("value").Funcs(valueFuncs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// soleExpression returns the pipeline of a string that is exactly one
+// {{ }} action, other than control structures.
+func soleExpression(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	inner, ok := strings.CutPrefix(s, "{{")
+	if !ok {
+		return "", false
+	}
+	inner, ok = strings.CutSuffix(inner, "}}")
+	if !ok || strings.Contains(inner, "{{") || strings.Contains(inner, "}}") {
+		return "", false
+	}
+	inner = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(inner, "-"), "-"))
+	word, _, _ := strings.Cut(inner, " ")
+	switch word {
+	case "", "if", "else", "end", "range", "with", "define", "template", "block", "break", "continue":
+		return "", false
+	}
+	if strings.HasPrefix(inner, "/*") {
+		return "", false
+	}
+	return inner, true
+}
+
+// evalExpression evaluates a template pipeline and returns its value rather
+// than its text.
+func evalExpression(expr string, data map[string]any) (any, error) {
+	var value any
+	funcs := template.FuncMap{"__value": func(v any) string {
+		value = v
+		return ""
+	}}
+	tmpl, err := template.New("value").Funcs(valueFuncs).Funcs(funcs).Parse("{{" + expr + " | __value}}")
+	if err != nil {
+		return nil, err
+	}
+	if err := tmpl.Execute(&bytes.Buffer{}, data); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// renderWorkflow applies the workflow's success or failure template to the
+// workflow data. The failure template also gets the error as .Error.
+func (s *SkillServer) renderWorkflow(ctx context.Context, w *RunningAction, data map[string]any, runErr error) (*pb.ExecuteActionResponse, error) {
+	ctx, span := tracer.Start(ctx, "render", trace.WithAttributes(actionAttr(w.Name)))
+	defer span.End()
+
+	name, text := "success", w.ResponseTemplate.Success
+	if runErr != nil {
+		name, text = "failure", w.ResponseTemplate.Failure
+		data["Error"] = runErr.Error()
+	}
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		slog.ErrorContext(ctx, "Workflow template parse error", "action", w.Name, "template", name, "error", err)
+		s.templateFailed(ctx, w.Name, name, err)
+		if runErr != nil {
+			return nil, runErr
+		}
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		slog.ErrorContext(ctx, "Workflow template execution error", "action", w.Name, "template", name, "error", err)
+		s.templateFailed(ctx, w.Name, name, err)
+		if runErr == nil {
+			return nil, err
+		}
+	}
+	return &pb.ExecuteActionResponse{Response: out.String()}, runErr
+}
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.36.5
+// 	protoc-gen-go v1.36.6
 // 	protoc        v5.29.3
 // source: proto/skill.proto
 
@@ -10,6 +10,7 @@ import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -113,6 +114,61 @@ func (ErrorCode) EnumDescriptor() ([]byte, []int) {
 	return file_proto_skill_proto_rawDescGZIP(), []int{0}
 }
 
+type JobState int32
+
+const (
+	JobState_JOB_STATE_UNSPECIFIED JobState = 0
+	JobState_JOB_RUNNING           JobState = 1
+	JobState_JOB_SUCCEEDED         JobState = 2
+	JobState_JOB_FAILED            JobState = 3
+	JobState_JOB_CANCELLED         JobState = 4
+)
+
+// Enum value maps for JobState.
+var (
+	JobState_name = map[int32]string{
+		0: "JOB_STATE_UNSPECIFIED",
+		1: "JOB_RUNNING",
+		2: "JOB_SUCCEEDED",
+		3: "JOB_FAILED",
+		4: "JOB_CANCELLED",
+	}
+	JobState_value = map[string]int32{
+		"JOB_STATE_UNSPECIFIED": 0,
+		"JOB_RUNNING":           1,
+		"JOB_SUCCEEDED":         2,
+		"JOB_FAILED":            3,
+		"JOB_CANCELLED":         4,
+	}
+)
+
+func (x JobState) Enum() *JobState {
+	p := new(JobState)
+	*p = x
+	return p
+}
+
+func (x JobState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JobState) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_skill_proto_enumTypes[1].Descriptor()
+}
+
+func (JobState) Type() protoreflect.EnumType {
+	return &file_proto_skill_proto_enumTypes[1]
+}
+
+func (x JobState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JobState.Descriptor instead.
+func (JobState) EnumDescriptor() ([]byte, []int) {
+	return file_proto_skill_proto_rawDescGZIP(), []int{1}
+}
+
 type GetActionRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Task          string                 `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
@@ -633,6 +689,7 @@ type ExecuteActionRequest struct {
 	QueryParams   *structpb.Struct       `protobuf:"bytes,2,opt,name=queryParams,proto3" json:"queryParams,omitempty"`
 	BodyParams    *structpb.Struct       `protobuf:"bytes,3,opt,name=bodyParams,proto3" json:"bodyParams,omitempty"` // Represent body parameters as a map
 	PathParams    *structpb.Struct       `protobuf:"bytes,4,opt,name=pathParams,proto3" json:"pathParams,omitempty"` // For parameters in the URL path
+	Async         bool                   `protobuf:"varint,5,opt,name=async,proto3" json:"async,omitempty"`          // Run as a background job and return the job at once
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -695,6 +752,13 @@ func (x *ExecuteActionRequest) GetPathParams() *structpb.Struct {
 	return nil
 }
 
+func (x *ExecuteActionRequest) GetAsync() bool {
+	if x != nil {
+		return x.Async
+	}
+	return false
+}
+
 type Error struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Code          ErrorCode              `protobuf:"varint,1,opt,name=code,proto3,enum=skill.ErrorCode" json:"code,omitempty"`
@@ -752,6 +816,9 @@ type ExecuteActionResponse struct {
 	Response      string                 `protobuf:"bytes,1,opt,name=response,proto3" json:"response,omitempty"`
 	Result        *Value                 `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
 	Error         *Error                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	Approval      *PendingApproval       `protobuf:"bytes,4,opt,name=approval,proto3" json:"approval,omitempty"`                  // Set when the action is held for approval
+	CacheHit      bool                   `protobuf:"varint,5,opt,name=cache_hit,json=cacheHit,proto3" json:"cache_hit,omitempty"` // The response was served from the response cache
+	Job           *Job                   `protobuf:"bytes,6,opt,name=job,proto3" json:"job,omitempty"`                            // Set when the call was started as a background job
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -807,139 +874,1216 @@ func (x *ExecuteActionResponse) GetError() *Error {
 	return nil
 }
 
+func (x *ExecuteActionResponse) GetApproval() *PendingApproval {
+	if x != nil {
+		return x.Approval
+	}
+	return nil
+}
+
+func (x *ExecuteActionResponse) GetCacheHit() bool {
+	if x != nil {
+		return x.CacheHit
+	}
+	return false
+}
+
+func (x *ExecuteActionResponse) GetJob() *Job {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+type BatchExecuteActionsRequest struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Requests      []*ExecuteActionRequest `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+	Parallelism   int32                   `protobuf:"varint,2,opt,name=parallelism,proto3" json:"parallelism,omitempty"`           // Concurrent calls; 0 or more than the skill allows uses the skill's limit
+	FailFast      bool                    `protobuf:"varint,3,opt,name=fail_fast,json=failFast,proto3" json:"fail_fast,omitempty"` // Cancel the remaining calls after the first failure instead of running them all
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchExecuteActionsRequest) Reset() {
+	*x = BatchExecuteActionsRequest{}
+	mi := &file_proto_skill_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchExecuteActionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchExecuteActionsRequest) ProtoMessage() {}
+
+func (x *BatchExecuteActionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_skill_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchExecuteActionsRequest.ProtoReflect.Descriptor instead.
+func (*BatchExecuteActionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_skill_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *BatchExecuteActionsRequest) GetRequests() []*ExecuteActionRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+func (x *BatchExecuteActionsRequest) GetParallelism() int32 {
+	if x != nil {
+		return x.Parallelism
+	}
+	return 0
+}
+
+func (x *BatchExecuteActionsRequest) GetFailFast() bool {
+	if x != nil {
+		return x.FailFast
+	}
+	return false
+}
+
+type BatchExecuteActionsResponse struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Responses     []*ExecuteActionResponse `protobuf:"bytes,1,rep,name=responses,proto3" json:"responses,omitempty"` // In request order; failed calls have error set
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchExecuteActionsResponse) Reset() {
+	*x = BatchExecuteActionsResponse{}
+	mi := &file_proto_skill_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchExecuteActionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchExecuteActionsResponse) ProtoMessage() {}
+
+func (x *BatchExecuteActionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_skill_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchExecuteActionsResponse.ProtoReflect.Descriptor instead.
+func (*BatchExecuteActionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_skill_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *BatchExecuteActionsResponse) GetResponses() []*ExecuteActionResponse {
+	if x != nil {
+		return x.Responses
+	}
+	return nil
+}
+
+// PendingApproval is a gated action call waiting for a human decision.
+type PendingApproval struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	Method        string                 `protobuf:"bytes,3,opt,name=method,proto3" json:"method,omitempty"`
+	Url           string                 `protobuf:"bytes,4,opt,name=url,proto3" json:"url,omitempty"`   // Planned request URL with path and query params applied
+	Body          string                 `protobuf:"bytes,5,opt,name=body,proto3" json:"body,omitempty"` // Planned request body
+	Headers       map[string]string      `protobuf:"bytes,6,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PendingApproval) Reset() {
+	*x = PendingApproval{}
+	mi := &file_proto_skill_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PendingApproval) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PendingApproval) ProtoMessage() {}
+
+func (x *PendingApproval) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_skill_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PendingApproval.ProtoReflect.Descriptor instead.
+func (*PendingApproval) Descriptor() ([]byte, []int) {
+	return file_proto_skill_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *PendingApproval) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PendingApproval) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *PendingApproval) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *PendingApproval) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *PendingApproval) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *PendingApproval) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *PendingApproval) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *PendingApproval) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type ApproveActionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApproveActionRequest) Reset() {
+	*x = ApproveActionRequest{}
+	mi := &file_proto_skill_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApproveActionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApproveActionRequest) ProtoMessage() {}
+
+func (x *ApproveActionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_skill_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApproveActionRequest.ProtoReflect.Descriptor instead.
+func (*ApproveActionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_skill_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ApproveActionRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RejectActionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RejectActionRequest) Reset() {
+	*x = RejectActionRequest{}
+	mi := &file_proto_skill_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RejectActionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RejectActionRequest) ProtoMessage() {}
+
+func (x *RejectActionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_skill_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RejectActionRequest.ProtoReflect.Descriptor instead.
+func (*RejectActionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_skill_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RejectActionRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RejectActionRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type RejectActionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Approval      *PendingApproval       `protobuf:"bytes,1,opt,name=approval,proto3" json:"approval,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RejectActionResponse) Reset() {
+	*x = RejectActionResponse{}
+	mi := &file_proto_skill_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RejectActionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RejectActionResponse) ProtoMessage() {}
+
+func (x *RejectActionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_skill_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RejectActionResponse.ProtoReflect.Descriptor instead.
+func (*RejectActionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_skill_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RejectActionResponse) GetApproval() *PendingApproval {
+	if x != nil {
+		return x.Approval
+	}
+	return nil
+}
+
+type ListPendingApprovalsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Action        string                 `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"` // Optional filter by action name
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPendingApprovalsRequest) Reset() {
+	*x = ListPendingApprovalsRequest{}
+	mi := &file_proto_skill_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPendingApprovalsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPendingApprovalsRequest) ProtoMessage() {}
+
+func (x *ListPendingApprovalsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_skill_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPendingApprovalsRequest.ProtoReflect.Descriptor instead.
+func (*ListPendingApprovalsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_skill_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListPendingApprovalsRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+type ListPendingApprovalsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Approvals     []*PendingApproval     `protobuf:"bytes,1,rep,name=approvals,proto3" json:"approvals,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPendingApprovalsResponse) Reset() {
+	*x = ListPendingApprovalsResponse{}
+	mi := &file_proto_skill_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPendingApprovalsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPendingApprovalsResponse) ProtoMessage() {}
+
+func (x *ListPendingApprovalsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_skill_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPendingApprovalsResponse.ProtoReflect.Descriptor instead.
+func (*ListPendingApprovalsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_skill_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListPendingApprovalsResponse) GetApprovals() []*PendingApproval {
+	if x != nil {
+		return x.Approvals
+	}
+	return nil
+}
+
+type PurgeCacheRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Action        string                 `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"` // Purge only this action's entries; empty purges everything
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurgeCacheRequest) Reset() {
+	*x = PurgeCacheRequest{}
+	mi := &file_proto_skill_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurgeCacheRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeCacheRequest) ProtoMessage() {}
+
+func (x *PurgeCacheRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_skill_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeCacheRequest.ProtoReflect.Descriptor instead.
+func (*PurgeCacheRequest) Descriptor() ([]byte, []int) {
+	return file_proto_skill_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *PurgeCacheRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+type PurgeCacheResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Purged        int32                  `protobuf:"varint,1,opt,name=purged,proto3" json:"purged,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurgeCacheResponse) Reset() {
+	*x = PurgeCacheResponse{}
+	mi := &file_proto_skill_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurgeCacheResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeCacheResponse) ProtoMessage() {}
+
+func (x *PurgeCacheResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_skill_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeCacheResponse.ProtoReflect.Descriptor instead.
+func (*PurgeCacheResponse) Descriptor() ([]byte, []int) {
+	return file_proto_skill_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *PurgeCacheResponse) GetPurged() int32 {
+	if x != nil {
+		return x.Purged
+	}
+	return 0
+}
+
+type GetCircuitBreakersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCircuitBreakersRequest) Reset() {
+	*x = GetCircuitBreakersRequest{}
+	mi := &file_proto_skill_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCircuitBreakersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCircuitBreakersRequest) ProtoMessage() {}
+
+func (x *GetCircuitBreakersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_skill_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCircuitBreakersRequest.ProtoReflect.Descriptor instead.
+func (*GetCircuitBreakersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_skill_proto_rawDescGZIP(), []int{20}
+}
+
+// CircuitBreaker is the state of the breaker guarding one upstream host or action.
+type CircuitBreaker struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Key                 string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`     // Upstream host or action name
+	State               string                 `protobuf:"bytes,2,opt,name=state,proto3" json:"state,omitempty"` // "closed", "open" or "half_open"
+	ConsecutiveFailures int32                  `protobuf:"varint,3,opt,name=consecutive_failures,json=consecutiveFailures,proto3" json:"consecutive_failures,omitempty"`
+	Trips               int64                  `protobuf:"varint,4,opt,name=trips,proto3" json:"trips,omitempty"` // Times the breaker has opened
+	LastError           string                 `protobuf:"bytes,5,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	OpenedAt            *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=opened_at,json=openedAt,proto3" json:"opened_at,omitempty"`
+	RetryAt             *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=retry_at,json=retryAt,proto3" json:"retry_at,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *CircuitBreaker) Reset() {
+	*x = CircuitBreaker{}
+	mi := &file_proto_skill_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CircuitBreaker) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CircuitBreaker) ProtoMessage() {}
+
+func (x *CircuitBreaker) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_skill_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CircuitBreaker.ProtoReflect.Descriptor instead.
+func (*CircuitBreaker) Descriptor() ([]byte, []int) {
+	return file_proto_skill_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CircuitBreaker) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *CircuitBreaker) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *CircuitBreaker) GetConsecutiveFailures() int32 {
+	if x != nil {
+		return x.ConsecutiveFailures
+	}
+	return 0
+}
+
+func (x *CircuitBreaker) GetTrips() int64 {
+	if x != nil {
+		return x.Trips
+	}
+	return 0
+}
+
+func (x *CircuitBreaker) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *CircuitBreaker) GetOpenedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OpenedAt
+	}
+	return nil
+}
+
+func (x *CircuitBreaker) GetRetryAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RetryAt
+	}
+	return nil
+}
+
+type GetCircuitBreakersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Breakers      []*CircuitBreaker      `protobuf:"bytes,1,rep,name=breakers,proto3" json:"breakers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCircuitBreakersResponse) Reset() {
+	*x = GetCircuitBreakersResponse{}
+	mi := &file_proto_skill_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCircuitBreakersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCircuitBreakersResponse) ProtoMessage() {}
+
+func (x *GetCircuitBreakersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_skill_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCircuitBreakersResponse.ProtoReflect.Descriptor instead.
+func (*GetCircuitBreakersResponse) Descriptor() ([]byte, []int) {
+	return file_proto_skill_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetCircuitBreakersResponse) GetBreakers() []*CircuitBreaker {
+	if x != nil {
+		return x.Breakers
+	}
+	return nil
+}
+
+// Job is an action call running in the background.
+type Job struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	State         JobState               `protobuf:"varint,3,opt,name=state,proto3,enum=skill.JobState" json:"state,omitempty"`
+	Response      string                 `protobuf:"bytes,4,opt,name=response,proto3" json:"response,omitempty"` // Rendered response template once the job has finished
+	Error         *Error                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`       // Set when the job failed or was cancelled
+	Caller        string                 `protobuf:"bytes,6,opt,name=caller,proto3" json:"caller,omitempty"`
+	RequestId     string                 `protobuf:"bytes,7,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Polling       bool                   `protobuf:"varint,8,opt,name=polling,proto3" json:"polling,omitempty"` // The upstream accepted the work and is being polled for the outcome
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	FinishedAt    *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Job) Reset() {
+	*x = Job{}
+	mi := &file_proto_skill_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Job) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job) ProtoMessage() {}
+
+func (x *Job) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_skill_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job.ProtoReflect.Descriptor instead.
+func (*Job) Descriptor() ([]byte, []int) {
+	return file_proto_skill_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *Job) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Job) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *Job) GetState() JobState {
+	if x != nil {
+		return x.State
+	}
+	return JobState_JOB_STATE_UNSPECIFIED
+}
+
+func (x *Job) GetResponse() string {
+	if x != nil {
+		return x.Response
+	}
+	return ""
+}
+
+func (x *Job) GetError() *Error {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+func (x *Job) GetCaller() string {
+	if x != nil {
+		return x.Caller
+	}
+	return ""
+}
+
+func (x *Job) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *Job) GetPolling() bool {
+	if x != nil {
+		return x.Polling
+	}
+	return false
+}
+
+func (x *Job) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Job) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Job) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+type GetJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobRequest) Reset() {
+	*x = GetJobRequest{}
+	mi := &file_proto_skill_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobRequest) ProtoMessage() {}
+
+func (x *GetJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_skill_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobRequest.ProtoReflect.Descriptor instead.
+func (*GetJobRequest) Descriptor() ([]byte, []int) {
+	return file_proto_skill_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GetJobRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type CancelJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelJobRequest) Reset() {
+	*x = CancelJobRequest{}
+	mi := &file_proto_skill_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelJobRequest) ProtoMessage() {}
+
+func (x *CancelJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_skill_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelJobRequest.ProtoReflect.Descriptor instead.
+func (*CancelJobRequest) Descriptor() ([]byte, []int) {
+	return file_proto_skill_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *CancelJobRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListJobsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Action        string                 `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`                    // Optional filter by action name
+	State         JobState               `protobuf:"varint,2,opt,name=state,proto3,enum=skill.JobState" json:"state,omitempty"` // Optional filter by state
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsRequest) Reset() {
+	*x = ListJobsRequest{}
+	mi := &file_proto_skill_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsRequest) ProtoMessage() {}
+
+func (x *ListJobsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_skill_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsRequest.ProtoReflect.Descriptor instead.
+func (*ListJobsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_skill_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ListJobsRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ListJobsRequest) GetState() JobState {
+	if x != nil {
+		return x.State
+	}
+	return JobState_JOB_STATE_UNSPECIFIED
+}
+
+type ListJobsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jobs          []*Job                 `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsResponse) Reset() {
+	*x = ListJobsResponse{}
+	mi := &file_proto_skill_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsResponse) ProtoMessage() {}
+
+func (x *ListJobsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_skill_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsResponse.ProtoReflect.Descriptor instead.
+func (*ListJobsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_skill_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ListJobsResponse) GetJobs() []*Job {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
 var File_proto_skill_proto protoreflect.FileDescriptor
 
-var file_proto_skill_proto_rawDesc = string([]byte{
-	0x0a, 0x11, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x73, 0x6b, 0x69, 0x6c, 0x6c, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x12, 0x05, 0x73, 0x6b, 0x69, 0x6c, 0x6c, 0x1a, 0x1c, 0x67, 0x6f, 0x6f, 0x67,
-	0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x73, 0x74, 0x72, 0x75,
-	0x63, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x26, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x41,
-	0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04,
-	0x74, 0x61, 0x73, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x73, 0x6b,
-	0x22, 0x3d, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x27, 0x0a, 0x07, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e,
-	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x73, 0x6b, 0x69, 0x6c, 0x6c, 0x2e,
-	0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22,
-	0xa0, 0x02, 0x0a, 0x06, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61,
-	0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x20,
-	0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e,
-	0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x62, 0x61, 0x73, 0x65,
-	0x55, 0x72, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x62, 0x61, 0x73, 0x65, 0x55,
-	0x72, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x28, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73,
-	0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x73, 0x6b, 0x69, 0x6c, 0x6c, 0x2e, 0x50,
-	0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x52, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73,
-	0x12, 0x34, 0x0a, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x1a, 0x2e, 0x73, 0x6b, 0x69, 0x6c, 0x6c, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e,
-	0x2e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x68,
-	0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x1a, 0x3a, 0x0a, 0x0c, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72,
-	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
-	0x38, 0x01, 0x22, 0xef, 0x01, 0x0a, 0x09, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72,
-	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
-	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x6e, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x6e, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63,
-	0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64,
-	0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65,
-	0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65,
-	0x71, 0x75, 0x69, 0x72, 0x65, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x65, 0x6e, 0x75, 0x6d, 0x18, 0x06,
-	0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x65, 0x6e, 0x75, 0x6d, 0x12, 0x30, 0x0a, 0x0a, 0x70, 0x72,
-	0x6f, 0x70, 0x65, 0x72, 0x74, 0x69, 0x65, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10,
-	0x2e, 0x73, 0x6b, 0x69, 0x6c, 0x6c, 0x2e, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72,
-	0x52, 0x0a, 0x70, 0x72, 0x6f, 0x70, 0x65, 0x72, 0x74, 0x69, 0x65, 0x73, 0x12, 0x26, 0x0a, 0x05,
-	0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x73, 0x6b,
-	0x69, 0x6c, 0x6c, 0x2e, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x52, 0x05, 0x69,
-	0x74, 0x65, 0x6d, 0x73, 0x22, 0xfa, 0x01, 0x0a, 0x05, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x23,
-	0x0a, 0x0c, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0b, 0x73, 0x74, 0x72, 0x69, 0x6e, 0x67, 0x56, 0x61,
-	0x6c, 0x75, 0x65, 0x12, 0x1d, 0x0a, 0x09, 0x69, 0x6e, 0x74, 0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x08, 0x69, 0x6e, 0x74, 0x56, 0x61, 0x6c,
-	0x75, 0x65, 0x12, 0x21, 0x0a, 0x0b, 0x66, 0x6c, 0x6f, 0x61, 0x74, 0x5f, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x48, 0x00, 0x52, 0x0a, 0x66, 0x6c, 0x6f, 0x61, 0x74,
-	0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x1f, 0x0a, 0x0a, 0x62, 0x6f, 0x6f, 0x6c, 0x5f, 0x76, 0x61,
-	0x6c, 0x75, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x48, 0x00, 0x52, 0x09, 0x62, 0x6f, 0x6f,
-	0x6c, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x31, 0x0a, 0x0a, 0x6c, 0x69, 0x73, 0x74, 0x5f, 0x76,
-	0x61, 0x6c, 0x75, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x73, 0x6b, 0x69,
-	0x6c, 0x6c, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x48, 0x00, 0x52, 0x09,
-	0x6c, 0x69, 0x73, 0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x2e, 0x0a, 0x09, 0x6d, 0x61, 0x70,
-	0x5f, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x73,
-	0x6b, 0x69, 0x6c, 0x6c, 0x2e, 0x4d, 0x61, 0x70, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x48, 0x00, 0x52,
-	0x08, 0x6d, 0x61, 0x70, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x42, 0x06, 0x0a, 0x04, 0x6b, 0x69, 0x6e,
-	0x64, 0x22, 0x31, 0x0a, 0x09, 0x4c, 0x69, 0x73, 0x74, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x24,
-	0x0a, 0x06, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c,
-	0x2e, 0x73, 0x6b, 0x69, 0x6c, 0x6c, 0x2e, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x06, 0x76, 0x61,
-	0x6c, 0x75, 0x65, 0x73, 0x22, 0x88, 0x01, 0x0a, 0x08, 0x4d, 0x61, 0x70, 0x56, 0x61, 0x6c, 0x75,
-	0x65, 0x12, 0x33, 0x0a, 0x06, 0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x1b, 0x2e, 0x73, 0x6b, 0x69, 0x6c, 0x6c, 0x2e, 0x4d, 0x61, 0x70, 0x56, 0x61, 0x6c,
-	0x75, 0x65, 0x2e, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x06,
-	0x66, 0x69, 0x65, 0x6c, 0x64, 0x73, 0x1a, 0x47, 0x0a, 0x0b, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x73,
-	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x22, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x73, 0x6b, 0x69, 0x6c, 0x6c, 0x2e, 0x56,
-	0x61, 0x6c, 0x75, 0x65, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22,
-	0xd7, 0x01, 0x0a, 0x14, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x41, 0x63, 0x74, 0x69, 0x6f,
-	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x39, 0x0a, 0x0b,
-	0x71, 0x75, 0x65, 0x72, 0x79, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x52, 0x0b, 0x71, 0x75, 0x65, 0x72,
-	0x79, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x12, 0x37, 0x0a, 0x0a, 0x62, 0x6f, 0x64, 0x79, 0x50,
-	0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f,
-	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74,
-	0x72, 0x75, 0x63, 0x74, 0x52, 0x0a, 0x62, 0x6f, 0x64, 0x79, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73,
-	0x12, 0x37, 0x0a, 0x0a, 0x70, 0x61, 0x74, 0x68, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x53, 0x74, 0x72, 0x75, 0x63, 0x74, 0x52, 0x0a, 0x70,
-	0x61, 0x74, 0x68, 0x50, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x22, 0x47, 0x0a, 0x05, 0x45, 0x72, 0x72,
-	0x6f, 0x72, 0x12, 0x24, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e,
-	0x32, 0x10, 0x2e, 0x73, 0x6b, 0x69, 0x6c, 0x6c, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x43, 0x6f,
-	0x64, 0x65, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61,
-	0x67, 0x65, 0x22, 0x7d, 0x0a, 0x15, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x41, 0x63, 0x74,
-	0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x72,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x72,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x24, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c,
-	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x73, 0x6b, 0x69, 0x6c, 0x6c, 0x2e,
-	0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x22, 0x0a,
-	0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x73,
-	0x6b, 0x69, 0x6c, 0x6c, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f,
-	0x72, 0x2a, 0xbc, 0x02, 0x0a, 0x09, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x43, 0x6f, 0x64, 0x65, 0x12,
-	0x06, 0x0a, 0x02, 0x4f, 0x4b, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09, 0x43, 0x41, 0x4e, 0x43, 0x45,
-	0x4c, 0x4c, 0x45, 0x44, 0x10, 0x01, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57,
-	0x4e, 0x10, 0x02, 0x12, 0x14, 0x0a, 0x10, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x5f, 0x41,
-	0x52, 0x47, 0x55, 0x4d, 0x45, 0x4e, 0x54, 0x10, 0x03, 0x12, 0x15, 0x0a, 0x11, 0x44, 0x45, 0x41,
-	0x44, 0x4c, 0x49, 0x4e, 0x45, 0x5f, 0x45, 0x58, 0x43, 0x45, 0x45, 0x44, 0x45, 0x44, 0x10, 0x04,
-	0x12, 0x0d, 0x0a, 0x09, 0x4e, 0x4f, 0x54, 0x5f, 0x46, 0x4f, 0x55, 0x4e, 0x44, 0x10, 0x05, 0x12,
-	0x12, 0x0a, 0x0e, 0x41, 0x4c, 0x52, 0x45, 0x41, 0x44, 0x59, 0x5f, 0x45, 0x58, 0x49, 0x53, 0x54,
-	0x53, 0x10, 0x06, 0x12, 0x15, 0x0a, 0x11, 0x50, 0x45, 0x52, 0x4d, 0x49, 0x53, 0x53, 0x49, 0x4f,
-	0x4e, 0x5f, 0x44, 0x45, 0x4e, 0x49, 0x45, 0x44, 0x10, 0x07, 0x12, 0x16, 0x0a, 0x12, 0x52, 0x45,
-	0x53, 0x4f, 0x55, 0x52, 0x43, 0x45, 0x5f, 0x45, 0x58, 0x48, 0x41, 0x55, 0x53, 0x54, 0x45, 0x44,
-	0x10, 0x08, 0x12, 0x17, 0x0a, 0x13, 0x46, 0x41, 0x49, 0x4c, 0x45, 0x44, 0x5f, 0x50, 0x52, 0x45,
-	0x43, 0x4f, 0x4e, 0x44, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x09, 0x12, 0x0b, 0x0a, 0x07, 0x41,
-	0x42, 0x4f, 0x52, 0x54, 0x45, 0x44, 0x10, 0x0a, 0x12, 0x10, 0x0a, 0x0c, 0x4f, 0x55, 0x54, 0x5f,
-	0x4f, 0x46, 0x5f, 0x52, 0x41, 0x4e, 0x47, 0x45, 0x10, 0x0b, 0x12, 0x11, 0x0a, 0x0d, 0x55, 0x4e,
-	0x49, 0x4d, 0x50, 0x4c, 0x45, 0x4d, 0x45, 0x4e, 0x54, 0x45, 0x44, 0x10, 0x0c, 0x12, 0x0c, 0x0a,
-	0x08, 0x49, 0x4e, 0x54, 0x45, 0x52, 0x4e, 0x41, 0x4c, 0x10, 0x0d, 0x12, 0x0f, 0x0a, 0x0b, 0x55,
-	0x4e, 0x41, 0x56, 0x41, 0x49, 0x4c, 0x41, 0x42, 0x4c, 0x45, 0x10, 0x0e, 0x12, 0x0d, 0x0a, 0x09,
-	0x44, 0x41, 0x54, 0x41, 0x5f, 0x4c, 0x4f, 0x53, 0x53, 0x10, 0x0f, 0x12, 0x13, 0x0a, 0x0f, 0x55,
-	0x4e, 0x41, 0x55, 0x54, 0x48, 0x45, 0x4e, 0x54, 0x49, 0x43, 0x41, 0x54, 0x45, 0x44, 0x10, 0x10,
-	0x32, 0x9c, 0x01, 0x0a, 0x0c, 0x53, 0x6b, 0x69, 0x6c, 0x6c, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63,
-	0x65, 0x12, 0x40, 0x0a, 0x0a, 0x47, 0x65, 0x74, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12,
-	0x17, 0x2e, 0x73, 0x6b, 0x69, 0x6c, 0x6c, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x63, 0x74, 0x69, 0x6f,
-	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x73, 0x6b, 0x69, 0x6c, 0x6c,
-	0x2e, 0x47, 0x65, 0x74, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x12, 0x4a, 0x0a, 0x0d, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x65, 0x41, 0x63,
-	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1b, 0x2e, 0x73, 0x6b, 0x69, 0x6c, 0x6c, 0x2e, 0x45, 0x78, 0x65,
-	0x63, 0x75, 0x74, 0x65, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x1c, 0x2e, 0x73, 0x6b, 0x69, 0x6c, 0x6c, 0x2e, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74,
-	0x65, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42,
-	0x09, 0x5a, 0x07, 0x2e, 0x3b, 0x73, 0x6b, 0x69, 0x6c, 0x6c, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x33,
-})
+const file_proto_skill_proto_rawDesc = "" +
+	"\n" +
+	"\x11proto/skill.proto\x12\x05skill\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"&\n" +
+	"\x10GetActionRequest\x12\x12\n" +
+	"\x04task\x18\x01 \x01(\tR\x04task\"=\n" +
+	"\x12GetActionsResponse\x12'\n" +
+	"\aactions\x18\x01 \x03(\v2\r.skill.ActionR\aactions\"\xa0\x02\n" +
+	"\x06Action\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x16\n" +
+	"\x06method\x18\x03 \x01(\tR\x06method\x12\x18\n" +
+	"\abaseUrl\x18\x04 \x01(\tR\abaseUrl\x12\x12\n" +
+	"\x04path\x18\x05 \x01(\tR\x04path\x12(\n" +
+	"\x06params\x18\x06 \x03(\v2\x10.skill.ParameterR\x06params\x124\n" +
+	"\aheaders\x18\a \x03(\v2\x1a.skill.Action.HeadersEntryR\aheaders\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xef\x01\n" +
+	"\tParameter\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x0e\n" +
+	"\x02in\x18\x03 \x01(\tR\x02in\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x1a\n" +
+	"\brequired\x18\x05 \x01(\bR\brequired\x12\x12\n" +
+	"\x04enum\x18\x06 \x03(\tR\x04enum\x120\n" +
+	"\n" +
+	"properties\x18\a \x03(\v2\x10.skill.ParameterR\n" +
+	"properties\x12&\n" +
+	"\x05items\x18\b \x03(\v2\x10.skill.ParameterR\x05items\"\xfa\x01\n" +
+	"\x05Value\x12#\n" +
+	"\fstring_value\x18\x01 \x01(\tH\x00R\vstringValue\x12\x1d\n" +
+	"\tint_value\x18\x02 \x01(\x03H\x00R\bintValue\x12!\n" +
+	"\vfloat_value\x18\x03 \x01(\x01H\x00R\n" +
+	"floatValue\x12\x1f\n" +
+	"\n" +
+	"bool_value\x18\x04 \x01(\bH\x00R\tboolValue\x121\n" +
+	"\n" +
+	"list_value\x18\x05 \x01(\v2\x10.skill.ListValueH\x00R\tlistValue\x12.\n" +
+	"\tmap_value\x18\x06 \x01(\v2\x0f.skill.MapValueH\x00R\bmapValueB\x06\n" +
+	"\x04kind\"1\n" +
+	"\tListValue\x12$\n" +
+	"\x06values\x18\x01 \x03(\v2\f.skill.ValueR\x06values\"\x88\x01\n" +
+	"\bMapValue\x123\n" +
+	"\x06fields\x18\x01 \x03(\v2\x1b.skill.MapValue.FieldsEntryR\x06fields\x1aG\n" +
+	"\vFieldsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\"\n" +
+	"\x05value\x18\x02 \x01(\v2\f.skill.ValueR\x05value:\x028\x01\"\xed\x01\n" +
+	"\x14ExecuteActionRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x129\n" +
+	"\vqueryParams\x18\x02 \x01(\v2\x17.google.protobuf.StructR\vqueryParams\x127\n" +
+	"\n" +
+	"bodyParams\x18\x03 \x01(\v2\x17.google.protobuf.StructR\n" +
+	"bodyParams\x127\n" +
+	"\n" +
+	"pathParams\x18\x04 \x01(\v2\x17.google.protobuf.StructR\n" +
+	"pathParams\x12\x14\n" +
+	"\x05async\x18\x05 \x01(\bR\x05async\"G\n" +
+	"\x05Error\x12$\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x10.skill.ErrorCodeR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xec\x01\n" +
+	"\x15ExecuteActionResponse\x12\x1a\n" +
+	"\bresponse\x18\x01 \x01(\tR\bresponse\x12$\n" +
+	"\x06result\x18\x02 \x01(\v2\f.skill.ValueR\x06result\x12\"\n" +
+	"\x05error\x18\x03 \x01(\v2\f.skill.ErrorR\x05error\x122\n" +
+	"\bapproval\x18\x04 \x01(\v2\x16.skill.PendingApprovalR\bapproval\x12\x1b\n" +
+	"\tcache_hit\x18\x05 \x01(\bR\bcacheHit\x12\x1c\n" +
+	"\x03job\x18\x06 \x01(\v2\n" +
+	".skill.JobR\x03job\"\x94\x01\n" +
+	"\x1aBatchExecuteActionsRequest\x127\n" +
+	"\brequests\x18\x01 \x03(\v2\x1b.skill.ExecuteActionRequestR\brequests\x12 \n" +
+	"\vparallelism\x18\x02 \x01(\x05R\vparallelism\x12\x1b\n" +
+	"\tfail_fast\x18\x03 \x01(\bR\bfailFast\"Y\n" +
+	"\x1bBatchExecuteActionsResponse\x12:\n" +
+	"\tresponses\x18\x01 \x03(\v2\x1c.skill.ExecuteActionResponseR\tresponses\"\xe8\x02\n" +
+	"\x0fPendingApproval\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x16\n" +
+	"\x06method\x18\x03 \x01(\tR\x06method\x12\x10\n" +
+	"\x03url\x18\x04 \x01(\tR\x03url\x12\x12\n" +
+	"\x04body\x18\x05 \x01(\tR\x04body\x12=\n" +
+	"\aheaders\x18\x06 \x03(\v2#.skill.PendingApproval.HeadersEntryR\aheaders\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"expires_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\texpiresAt\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"&\n" +
+	"\x14ApproveActionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"=\n" +
+	"\x13RejectActionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"J\n" +
+	"\x14RejectActionResponse\x122\n" +
+	"\bapproval\x18\x01 \x01(\v2\x16.skill.PendingApprovalR\bapproval\"5\n" +
+	"\x1bListPendingApprovalsRequest\x12\x16\n" +
+	"\x06action\x18\x01 \x01(\tR\x06action\"T\n" +
+	"\x1cListPendingApprovalsResponse\x124\n" +
+	"\tapprovals\x18\x01 \x03(\v2\x16.skill.PendingApprovalR\tapprovals\"+\n" +
+	"\x11PurgeCacheRequest\x12\x16\n" +
+	"\x06action\x18\x01 \x01(\tR\x06action\",\n" +
+	"\x12PurgeCacheResponse\x12\x16\n" +
+	"\x06purged\x18\x01 \x01(\x05R\x06purged\"\x1b\n" +
+	"\x19GetCircuitBreakersRequest\"\x90\x02\n" +
+	"\x0eCircuitBreaker\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05state\x18\x02 \x01(\tR\x05state\x121\n" +
+	"\x14consecutive_failures\x18\x03 \x01(\x05R\x13consecutiveFailures\x12\x14\n" +
+	"\x05trips\x18\x04 \x01(\x03R\x05trips\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x05 \x01(\tR\tlastError\x127\n" +
+	"\topened_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\bopenedAt\x125\n" +
+	"\bretry_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\aretryAt\"O\n" +
+	"\x1aGetCircuitBreakersResponse\x121\n" +
+	"\bbreakers\x18\x01 \x03(\v2\x15.skill.CircuitBreakerR\bbreakers\"\x98\x03\n" +
+	"\x03Job\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12%\n" +
+	"\x05state\x18\x03 \x01(\x0e2\x0f.skill.JobStateR\x05state\x12\x1a\n" +
+	"\bresponse\x18\x04 \x01(\tR\bresponse\x12\"\n" +
+	"\x05error\x18\x05 \x01(\v2\f.skill.ErrorR\x05error\x12\x16\n" +
+	"\x06caller\x18\x06 \x01(\tR\x06caller\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\a \x01(\tR\trequestId\x12\x18\n" +
+	"\apolling\x18\b \x01(\bR\apolling\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12;\n" +
+	"\vfinished_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\"\x1f\n" +
+	"\rGetJobRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\"\n" +
+	"\x10CancelJobRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"P\n" +
+	"\x0fListJobsRequest\x12\x16\n" +
+	"\x06action\x18\x01 \x01(\tR\x06action\x12%\n" +
+	"\x05state\x18\x02 \x01(\x0e2\x0f.skill.JobStateR\x05state\"2\n" +
+	"\x10ListJobsResponse\x12\x1e\n" +
+	"\x04jobs\x18\x01 \x03(\v2\n" +
+	".skill.JobR\x04jobs*\xbc\x02\n" +
+	"\tErrorCode\x12\x06\n" +
+	"\x02OK\x10\x00\x12\r\n" +
+	"\tCANCELLED\x10\x01\x12\v\n" +
+	"\aUNKNOWN\x10\x02\x12\x14\n" +
+	"\x10INVALID_ARGUMENT\x10\x03\x12\x15\n" +
+	"\x11DEADLINE_EXCEEDED\x10\x04\x12\r\n" +
+	"\tNOT_FOUND\x10\x05\x12\x12\n" +
+	"\x0eALREADY_EXISTS\x10\x06\x12\x15\n" +
+	"\x11PERMISSION_DENIED\x10\a\x12\x16\n" +
+	"\x12RESOURCE_EXHAUSTED\x10\b\x12\x17\n" +
+	"\x13FAILED_PRECONDITION\x10\t\x12\v\n" +
+	"\aABORTED\x10\n" +
+	"\x12\x10\n" +
+	"\fOUT_OF_RANGE\x10\v\x12\x11\n" +
+	"\rUNIMPLEMENTED\x10\f\x12\f\n" +
+	"\bINTERNAL\x10\r\x12\x0f\n" +
+	"\vUNAVAILABLE\x10\x0e\x12\r\n" +
+	"\tDATA_LOSS\x10\x0f\x12\x13\n" +
+	"\x0fUNAUTHENTICATED\x10\x10*l\n" +
+	"\bJobState\x12\x19\n" +
+	"\x15JOB_STATE_UNSPECIFIED\x10\x00\x12\x0f\n" +
+	"\vJOB_RUNNING\x10\x01\x12\x11\n" +
+	"\rJOB_SUCCEEDED\x10\x02\x12\x0e\n" +
+	"\n" +
+	"JOB_FAILED\x10\x03\x12\x11\n" +
+	"\rJOB_CANCELLED\x10\x042\xa9\x06\n" +
+	"\fSkillService\x12@\n" +
+	"\n" +
+	"GetActions\x12\x17.skill.GetActionRequest\x1a\x19.skill.GetActionsResponse\x12J\n" +
+	"\rExecuteAction\x12\x1b.skill.ExecuteActionRequest\x1a\x1c.skill.ExecuteActionResponse\x12\\\n" +
+	"\x13BatchExecuteActions\x12!.skill.BatchExecuteActionsRequest\x1a\".skill.BatchExecuteActionsResponse\x12J\n" +
+	"\rApproveAction\x12\x1b.skill.ApproveActionRequest\x1a\x1c.skill.ExecuteActionResponse\x12G\n" +
+	"\fRejectAction\x12\x1a.skill.RejectActionRequest\x1a\x1b.skill.RejectActionResponse\x12_\n" +
+	"\x14ListPendingApprovals\x12\".skill.ListPendingApprovalsRequest\x1a#.skill.ListPendingApprovalsResponse\x12A\n" +
+	"\n" +
+	"PurgeCache\x12\x18.skill.PurgeCacheRequest\x1a\x19.skill.PurgeCacheResponse\x12Y\n" +
+	"\x12GetCircuitBreakers\x12 .skill.GetCircuitBreakersRequest\x1a!.skill.GetCircuitBreakersResponse\x12*\n" +
+	"\x06GetJob\x12\x14.skill.GetJobRequest\x1a\n" +
+	".skill.Job\x120\n" +
+	"\tCancelJob\x12\x17.skill.CancelJobRequest\x1a\n" +
+	".skill.Job\x12;\n" +
+	"\bListJobs\x12\x16.skill.ListJobsRequest\x1a\x17.skill.ListJobsResponseB\tZ\a.;skillb\x06proto3"
 
 var (
 	file_proto_skill_proto_rawDescOnce sync.Once
@@ -953,50 +2097,108 @@ func file_proto_skill_proto_rawDescGZIP() []byte {
 	return file_proto_skill_proto_rawDescData
 }
 
-var file_proto_skill_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_proto_skill_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_proto_skill_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_proto_skill_proto_msgTypes = make([]protoimpl.MessageInfo, 31)
 var file_proto_skill_proto_goTypes = []any{
-	(ErrorCode)(0),                // 0: skill.ErrorCode
-	(*GetActionRequest)(nil),      // 1: skill.GetActionRequest
-	(*GetActionsResponse)(nil),    // 2: skill.GetActionsResponse
-	(*Action)(nil),                // 3: skill.Action
-	(*Parameter)(nil),             // 4: skill.Parameter
-	(*Value)(nil),                 // 5: skill.Value
-	(*ListValue)(nil),             // 6: skill.ListValue
-	(*MapValue)(nil),              // 7: skill.MapValue
-	(*ExecuteActionRequest)(nil),  // 8: skill.ExecuteActionRequest
-	(*Error)(nil),                 // 9: skill.Error
-	(*ExecuteActionResponse)(nil), // 10: skill.ExecuteActionResponse
-	nil,                           // 11: skill.Action.HeadersEntry
-	nil,                           // 12: skill.MapValue.FieldsEntry
-	(*structpb.Struct)(nil),       // 13: google.protobuf.Struct
+	(ErrorCode)(0),                       // 0: skill.ErrorCode
+	(JobState)(0),                        // 1: skill.JobState
+	(*GetActionRequest)(nil),             // 2: skill.GetActionRequest
+	(*GetActionsResponse)(nil),           // 3: skill.GetActionsResponse
+	(*Action)(nil),                       // 4: skill.Action
+	(*Parameter)(nil),                    // 5: skill.Parameter
+	(*Value)(nil),                        // 6: skill.Value
+	(*ListValue)(nil),                    // 7: skill.ListValue
+	(*MapValue)(nil),                     // 8: skill.MapValue
+	(*ExecuteActionRequest)(nil),         // 9: skill.ExecuteActionRequest
+	(*Error)(nil),                        // 10: skill.Error
+	(*ExecuteActionResponse)(nil),        // 11: skill.ExecuteActionResponse
+	(*BatchExecuteActionsRequest)(nil),   // 12: skill.BatchExecuteActionsRequest
+	(*BatchExecuteActionsResponse)(nil),  // 13: skill.BatchExecuteActionsResponse
+	(*PendingApproval)(nil),              // 14: skill.PendingApproval
+	(*ApproveActionRequest)(nil),         // 15: skill.ApproveActionRequest
+	(*RejectActionRequest)(nil),          // 16: skill.RejectActionRequest
+	(*RejectActionResponse)(nil),         // 17: skill.RejectActionResponse
+	(*ListPendingApprovalsRequest)(nil),  // 18: skill.ListPendingApprovalsRequest
+	(*ListPendingApprovalsResponse)(nil), // 19: skill.ListPendingApprovalsResponse
+	(*PurgeCacheRequest)(nil),            // 20: skill.PurgeCacheRequest
+	(*PurgeCacheResponse)(nil),           // 21: skill.PurgeCacheResponse
+	(*GetCircuitBreakersRequest)(nil),    // 22: skill.GetCircuitBreakersRequest
+	(*CircuitBreaker)(nil),               // 23: skill.CircuitBreaker
+	(*GetCircuitBreakersResponse)(nil),   // 24: skill.GetCircuitBreakersResponse
+	(*Job)(nil),                          // 25: skill.Job
+	(*GetJobRequest)(nil),                // 26: skill.GetJobRequest
+	(*CancelJobRequest)(nil),             // 27: skill.CancelJobRequest
+	(*ListJobsRequest)(nil),              // 28: skill.ListJobsRequest
+	(*ListJobsResponse)(nil),             // 29: skill.ListJobsResponse
+	nil,                                  // 30: skill.Action.HeadersEntry
+	nil,                                  // 31: skill.MapValue.FieldsEntry
+	nil,                                  // 32: skill.PendingApproval.HeadersEntry
+	(*structpb.Struct)(nil),              // 33: google.protobuf.Struct
+	(*timestamppb.Timestamp)(nil),        // 34: google.protobuf.Timestamp
 }
 var file_proto_skill_proto_depIdxs = []int32{
-	3,  // 0: skill.GetActionsResponse.actions:type_name -> skill.Action
-	4,  // 1: skill.Action.params:type_name -> skill.Parameter
-	11, // 2: skill.Action.headers:type_name -> skill.Action.HeadersEntry
-	4,  // 3: skill.Parameter.properties:type_name -> skill.Parameter
-	4,  // 4: skill.Parameter.items:type_name -> skill.Parameter
-	6,  // 5: skill.Value.list_value:type_name -> skill.ListValue
-	7,  // 6: skill.Value.map_value:type_name -> skill.MapValue
-	5,  // 7: skill.ListValue.values:type_name -> skill.Value
-	12, // 8: skill.MapValue.fields:type_name -> skill.MapValue.FieldsEntry
-	13, // 9: skill.ExecuteActionRequest.queryParams:type_name -> google.protobuf.Struct
-	13, // 10: skill.ExecuteActionRequest.bodyParams:type_name -> google.protobuf.Struct
-	13, // 11: skill.ExecuteActionRequest.pathParams:type_name -> google.protobuf.Struct
+	4,  // 0: skill.GetActionsResponse.actions:type_name -> skill.Action
+	5,  // 1: skill.Action.params:type_name -> skill.Parameter
+	30, // 2: skill.Action.headers:type_name -> skill.Action.HeadersEntry
+	5,  // 3: skill.Parameter.properties:type_name -> skill.Parameter
+	5,  // 4: skill.Parameter.items:type_name -> skill.Parameter
+	7,  // 5: skill.Value.list_value:type_name -> skill.ListValue
+	8,  // 6: skill.Value.map_value:type_name -> skill.MapValue
+	6,  // 7: skill.ListValue.values:type_name -> skill.Value
+	31, // 8: skill.MapValue.fields:type_name -> skill.MapValue.FieldsEntry
+	33, // 9: skill.ExecuteActionRequest.queryParams:type_name -> google.protobuf.Struct
+	33, // 10: skill.ExecuteActionRequest.bodyParams:type_name -> google.protobuf.Struct
+	33, // 11: skill.ExecuteActionRequest.pathParams:type_name -> google.protobuf.Struct
 	0,  // 12: skill.Error.code:type_name -> skill.ErrorCode
-	5,  // 13: skill.ExecuteActionResponse.result:type_name -> skill.Value
-	9,  // 14: skill.ExecuteActionResponse.error:type_name -> skill.Error
-	5,  // 15: skill.MapValue.FieldsEntry.value:type_name -> skill.Value
-	1,  // 16: skill.SkillService.GetActions:input_type -> skill.GetActionRequest
-	8,  // 17: skill.SkillService.ExecuteAction:input_type -> skill.ExecuteActionRequest
-	2,  // 18: skill.SkillService.GetActions:output_type -> skill.GetActionsResponse
-	10, // 19: skill.SkillService.ExecuteAction:output_type -> skill.ExecuteActionResponse
-	18, // [18:20] is the sub-list for method output_type
-	16, // [16:18] is the sub-list for method input_type
-	16, // [16:16] is the sub-list for extension type_name
-	16, // [16:16] is the sub-list for extension extendee
-	0,  // [0:16] is the sub-list for field type_name
+	6,  // 13: skill.ExecuteActionResponse.result:type_name -> skill.Value
+	10, // 14: skill.ExecuteActionResponse.error:type_name -> skill.Error
+	14, // 15: skill.ExecuteActionResponse.approval:type_name -> skill.PendingApproval
+	25, // 16: skill.ExecuteActionResponse.job:type_name -> skill.Job
+	9,  // 17: skill.BatchExecuteActionsRequest.requests:type_name -> skill.ExecuteActionRequest
+	11, // 18: skill.BatchExecuteActionsResponse.responses:type_name -> skill.ExecuteActionResponse
+	32, // 19: skill.PendingApproval.headers:type_name -> skill.PendingApproval.HeadersEntry
+	34, // 20: skill.PendingApproval.created_at:type_name -> google.protobuf.Timestamp
+	34, // 21: skill.PendingApproval.expires_at:type_name -> google.protobuf.Timestamp
+	14, // 22: skill.RejectActionResponse.approval:type_name -> skill.PendingApproval
+	14, // 23: skill.ListPendingApprovalsResponse.approvals:type_name -> skill.PendingApproval
+	34, // 24: skill.CircuitBreaker.opened_at:type_name -> google.protobuf.Timestamp
+	34, // 25: skill.CircuitBreaker.retry_at:type_name -> google.protobuf.Timestamp
+	23, // 26: skill.GetCircuitBreakersResponse.breakers:type_name -> skill.CircuitBreaker
+	1,  // 27: skill.Job.state:type_name -> skill.JobState
+	10, // 28: skill.Job.error:type_name -> skill.Error
+	34, // 29: skill.Job.created_at:type_name -> google.protobuf.Timestamp
+	34, // 30: skill.Job.updated_at:type_name -> google.protobuf.Timestamp
+	34, // 31: skill.Job.finished_at:type_name -> google.protobuf.Timestamp
+	1,  // 32: skill.ListJobsRequest.state:type_name -> skill.JobState
+	25, // 33: skill.ListJobsResponse.jobs:type_name -> skill.Job
+	6,  // 34: skill.MapValue.FieldsEntry.value:type_name -> skill.Value
+	2,  // 35: skill.SkillService.GetActions:input_type -> skill.GetActionRequest
+	9,  // 36: skill.SkillService.ExecuteAction:input_type -> skill.ExecuteActionRequest
+	12, // 37: skill.SkillService.BatchExecuteActions:input_type -> skill.BatchExecuteActionsRequest
+	15, // 38: skill.SkillService.ApproveAction:input_type -> skill.ApproveActionRequest
+	16, // 39: skill.SkillService.RejectAction:input_type -> skill.RejectActionRequest
+	18, // 40: skill.SkillService.ListPendingApprovals:input_type -> skill.ListPendingApprovalsRequest
+	20, // 41: skill.SkillService.PurgeCache:input_type -> skill.PurgeCacheRequest
+	22, // 42: skill.SkillService.GetCircuitBreakers:input_type -> skill.GetCircuitBreakersRequest
+	26, // 43: skill.SkillService.GetJob:input_type -> skill.GetJobRequest
+	27, // 44: skill.SkillService.CancelJob:input_type -> skill.CancelJobRequest
+	28, // 45: skill.SkillService.ListJobs:input_type -> skill.ListJobsRequest
+	3,  // 46: skill.SkillService.GetActions:output_type -> skill.GetActionsResponse
+	11, // 47: skill.SkillService.ExecuteAction:output_type -> skill.ExecuteActionResponse
+	13, // 48: skill.SkillService.BatchExecuteActions:output_type -> skill.BatchExecuteActionsResponse
+	11, // 49: skill.SkillService.ApproveAction:output_type -> skill.ExecuteActionResponse
+	17, // 50: skill.SkillService.RejectAction:output_type -> skill.RejectActionResponse
+	19, // 51: skill.SkillService.ListPendingApprovals:output_type -> skill.ListPendingApprovalsResponse
+	21, // 52: skill.SkillService.PurgeCache:output_type -> skill.PurgeCacheResponse
+	24, // 53: skill.SkillService.GetCircuitBreakers:output_type -> skill.GetCircuitBreakersResponse
+	25, // 54: skill.SkillService.GetJob:output_type -> skill.Job
+	25, // 55: skill.SkillService.CancelJob:output_type -> skill.Job
+	29, // 56: skill.SkillService.ListJobs:output_type -> skill.ListJobsResponse
+	46, // [46:57] is the sub-list for method output_type
+	35, // [35:46] is the sub-list for method input_type
+	35, // [35:35] is the sub-list for extension type_name
+	35, // [35:35] is the sub-list for extension extendee
+	0,  // [0:35] is the sub-list for field type_name
 }
 
 func init() { file_proto_skill_proto_init() }
@@ -1017,8 +2219,8 @@ func file_proto_skill_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_skill_proto_rawDesc), len(file_proto_skill_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   12,
+			NumEnums:      2,
+			NumMessages:   31,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
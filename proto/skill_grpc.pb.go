@@ -19,8 +19,17 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	SkillService_GetActions_FullMethodName    = "/skill.SkillService/GetActions"
-	SkillService_ExecuteAction_FullMethodName = "/skill.SkillService/ExecuteAction"
+	SkillService_GetActions_FullMethodName           = "/skill.SkillService/GetActions"
+	SkillService_ExecuteAction_FullMethodName        = "/skill.SkillService/ExecuteAction"
+	SkillService_BatchExecuteActions_FullMethodName  = "/skill.SkillService/BatchExecuteActions"
+	SkillService_ApproveAction_FullMethodName        = "/skill.SkillService/ApproveAction"
+	SkillService_RejectAction_FullMethodName         = "/skill.SkillService/RejectAction"
+	SkillService_ListPendingApprovals_FullMethodName = "/skill.SkillService/ListPendingApprovals"
+	SkillService_PurgeCache_FullMethodName           = "/skill.SkillService/PurgeCache"
+	SkillService_GetCircuitBreakers_FullMethodName   = "/skill.SkillService/GetCircuitBreakers"
+	SkillService_GetJob_FullMethodName               = "/skill.SkillService/GetJob"
+	SkillService_CancelJob_FullMethodName            = "/skill.SkillService/CancelJob"
+	SkillService_ListJobs_FullMethodName             = "/skill.SkillService/ListJobs"
 )
 
 // SkillServiceClient is the client API for SkillService service.
@@ -29,6 +38,15 @@ const (
 type SkillServiceClient interface {
 	GetActions(ctx context.Context, in *GetActionRequest, opts ...grpc.CallOption) (*GetActionsResponse, error)
 	ExecuteAction(ctx context.Context, in *ExecuteActionRequest, opts ...grpc.CallOption) (*ExecuteActionResponse, error)
+	BatchExecuteActions(ctx context.Context, in *BatchExecuteActionsRequest, opts ...grpc.CallOption) (*BatchExecuteActionsResponse, error)
+	ApproveAction(ctx context.Context, in *ApproveActionRequest, opts ...grpc.CallOption) (*ExecuteActionResponse, error)
+	RejectAction(ctx context.Context, in *RejectActionRequest, opts ...grpc.CallOption) (*RejectActionResponse, error)
+	ListPendingApprovals(ctx context.Context, in *ListPendingApprovalsRequest, opts ...grpc.CallOption) (*ListPendingApprovalsResponse, error)
+	PurgeCache(ctx context.Context, in *PurgeCacheRequest, opts ...grpc.CallOption) (*PurgeCacheResponse, error)
+	GetCircuitBreakers(ctx context.Context, in *GetCircuitBreakersRequest, opts ...grpc.CallOption) (*GetCircuitBreakersResponse, error)
+	GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error)
+	CancelJob(ctx context.Context, in *CancelJobRequest, opts ...grpc.CallOption) (*Job, error)
+	ListJobs(ctx context.Context, in *ListJobsRequest, opts ...grpc.CallOption) (*ListJobsResponse, error)
 }
 
 type skillServiceClient struct {
@@ -59,12 +77,111 @@ func (c *skillServiceClient) ExecuteAction(ctx context.Context, in *ExecuteActio
 	return out, nil
 }
 
+func (c *skillServiceClient) BatchExecuteActions(ctx context.Context, in *BatchExecuteActionsRequest, opts ...grpc.CallOption) (*BatchExecuteActionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchExecuteActionsResponse)
+	err := c.cc.Invoke(ctx, SkillService_BatchExecuteActions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skillServiceClient) ApproveAction(ctx context.Context, in *ApproveActionRequest, opts ...grpc.CallOption) (*ExecuteActionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExecuteActionResponse)
+	err := c.cc.Invoke(ctx, SkillService_ApproveAction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skillServiceClient) RejectAction(ctx context.Context, in *RejectActionRequest, opts ...grpc.CallOption) (*RejectActionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RejectActionResponse)
+	err := c.cc.Invoke(ctx, SkillService_RejectAction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skillServiceClient) ListPendingApprovals(ctx context.Context, in *ListPendingApprovalsRequest, opts ...grpc.CallOption) (*ListPendingApprovalsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPendingApprovalsResponse)
+	err := c.cc.Invoke(ctx, SkillService_ListPendingApprovals_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skillServiceClient) PurgeCache(ctx context.Context, in *PurgeCacheRequest, opts ...grpc.CallOption) (*PurgeCacheResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PurgeCacheResponse)
+	err := c.cc.Invoke(ctx, SkillService_PurgeCache_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skillServiceClient) GetCircuitBreakers(ctx context.Context, in *GetCircuitBreakersRequest, opts ...grpc.CallOption) (*GetCircuitBreakersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetCircuitBreakersResponse)
+	err := c.cc.Invoke(ctx, SkillService_GetCircuitBreakers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skillServiceClient) GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, SkillService_GetJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skillServiceClient) CancelJob(ctx context.Context, in *CancelJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, SkillService_CancelJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *skillServiceClient) ListJobs(ctx context.Context, in *ListJobsRequest, opts ...grpc.CallOption) (*ListJobsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListJobsResponse)
+	err := c.cc.Invoke(ctx, SkillService_ListJobs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SkillServiceServer is the server API for SkillService service.
 // All implementations must embed UnimplementedSkillServiceServer
 // for forward compatibility.
 type SkillServiceServer interface {
 	GetActions(context.Context, *GetActionRequest) (*GetActionsResponse, error)
 	ExecuteAction(context.Context, *ExecuteActionRequest) (*ExecuteActionResponse, error)
+	BatchExecuteActions(context.Context, *BatchExecuteActionsRequest) (*BatchExecuteActionsResponse, error)
+	ApproveAction(context.Context, *ApproveActionRequest) (*ExecuteActionResponse, error)
+	RejectAction(context.Context, *RejectActionRequest) (*RejectActionResponse, error)
+	ListPendingApprovals(context.Context, *ListPendingApprovalsRequest) (*ListPendingApprovalsResponse, error)
+	PurgeCache(context.Context, *PurgeCacheRequest) (*PurgeCacheResponse, error)
+	GetCircuitBreakers(context.Context, *GetCircuitBreakersRequest) (*GetCircuitBreakersResponse, error)
+	GetJob(context.Context, *GetJobRequest) (*Job, error)
+	CancelJob(context.Context, *CancelJobRequest) (*Job, error)
+	ListJobs(context.Context, *ListJobsRequest) (*ListJobsResponse, error)
 	mustEmbedUnimplementedSkillServiceServer()
 }
 
@@ -81,6 +198,33 @@ func (UnimplementedSkillServiceServer) GetActions(context.Context, *GetActionReq
 func (UnimplementedSkillServiceServer) ExecuteAction(context.Context, *ExecuteActionRequest) (*ExecuteActionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ExecuteAction not implemented")
 }
+func (UnimplementedSkillServiceServer) BatchExecuteActions(context.Context, *BatchExecuteActionsRequest) (*BatchExecuteActionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchExecuteActions not implemented")
+}
+func (UnimplementedSkillServiceServer) ApproveAction(context.Context, *ApproveActionRequest) (*ExecuteActionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApproveAction not implemented")
+}
+func (UnimplementedSkillServiceServer) RejectAction(context.Context, *RejectActionRequest) (*RejectActionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RejectAction not implemented")
+}
+func (UnimplementedSkillServiceServer) ListPendingApprovals(context.Context, *ListPendingApprovalsRequest) (*ListPendingApprovalsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPendingApprovals not implemented")
+}
+func (UnimplementedSkillServiceServer) PurgeCache(context.Context, *PurgeCacheRequest) (*PurgeCacheResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeCache not implemented")
+}
+func (UnimplementedSkillServiceServer) GetCircuitBreakers(context.Context, *GetCircuitBreakersRequest) (*GetCircuitBreakersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCircuitBreakers not implemented")
+}
+func (UnimplementedSkillServiceServer) GetJob(context.Context, *GetJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJob not implemented")
+}
+func (UnimplementedSkillServiceServer) CancelJob(context.Context, *CancelJobRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelJob not implemented")
+}
+func (UnimplementedSkillServiceServer) ListJobs(context.Context, *ListJobsRequest) (*ListJobsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListJobs not implemented")
+}
 func (UnimplementedSkillServiceServer) mustEmbedUnimplementedSkillServiceServer() {}
 func (UnimplementedSkillServiceServer) testEmbeddedByValue()                      {}
 
@@ -138,6 +282,168 @@ func _SkillService_ExecuteAction_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SkillService_BatchExecuteActions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchExecuteActionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SkillServiceServer).BatchExecuteActions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SkillService_BatchExecuteActions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkillServiceServer).BatchExecuteActions(ctx, req.(*BatchExecuteActionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SkillService_ApproveAction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApproveActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SkillServiceServer).ApproveAction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SkillService_ApproveAction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkillServiceServer).ApproveAction(ctx, req.(*ApproveActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SkillService_RejectAction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RejectActionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SkillServiceServer).RejectAction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SkillService_RejectAction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkillServiceServer).RejectAction(ctx, req.(*RejectActionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SkillService_ListPendingApprovals_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPendingApprovalsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SkillServiceServer).ListPendingApprovals(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SkillService_ListPendingApprovals_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkillServiceServer).ListPendingApprovals(ctx, req.(*ListPendingApprovalsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SkillService_PurgeCache_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeCacheRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SkillServiceServer).PurgeCache(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SkillService_PurgeCache_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkillServiceServer).PurgeCache(ctx, req.(*PurgeCacheRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SkillService_GetCircuitBreakers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCircuitBreakersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SkillServiceServer).GetCircuitBreakers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SkillService_GetCircuitBreakers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkillServiceServer).GetCircuitBreakers(ctx, req.(*GetCircuitBreakersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SkillService_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SkillServiceServer).GetJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SkillService_GetJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkillServiceServer).GetJob(ctx, req.(*GetJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SkillService_CancelJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SkillServiceServer).CancelJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SkillService_CancelJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkillServiceServer).CancelJob(ctx, req.(*CancelJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SkillService_ListJobs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListJobsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SkillServiceServer).ListJobs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SkillService_ListJobs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SkillServiceServer).ListJobs(ctx, req.(*ListJobsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // SkillService_ServiceDesc is the grpc.ServiceDesc for SkillService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -153,6 +459,42 @@ var SkillService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ExecuteAction",
 			Handler:    _SkillService_ExecuteAction_Handler,
 		},
+		{
+			MethodName: "BatchExecuteActions",
+			Handler:    _SkillService_BatchExecuteActions_Handler,
+		},
+		{
+			MethodName: "ApproveAction",
+			Handler:    _SkillService_ApproveAction_Handler,
+		},
+		{
+			MethodName: "RejectAction",
+			Handler:    _SkillService_RejectAction_Handler,
+		},
+		{
+			MethodName: "ListPendingApprovals",
+			Handler:    _SkillService_ListPendingApprovals_Handler,
+		},
+		{
+			MethodName: "PurgeCache",
+			Handler:    _SkillService_PurgeCache_Handler,
+		},
+		{
+			MethodName: "GetCircuitBreakers",
+			Handler:    _SkillService_GetCircuitBreakers_Handler,
+		},
+		{
+			MethodName: "GetJob",
+			Handler:    _SkillService_GetJob_Handler,
+		},
+		{
+			MethodName: "CancelJob",
+			Handler:    _SkillService_CancelJob_Handler,
+		},
+		{
+			MethodName: "ListJobs",
+			Handler:    _SkillService_ListJobs_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/skill.proto",
@@ -0,0 +1,87 @@
+package redact
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// urlAttributes hold a URL, or the path and query of one, in the HTTP
+// semantic conventions.
+var urlAttributes = map[attribute.Key]bool{
+	"url.full":    true,
+	"url.path":    true,
+	"http.url":    true,
+	"http.target": true,
+}
+
+// SpanExporter is a trace exporter that redacts span status, error events
+// and URL attributes before passing spans to the wrapped exporter.
+type SpanExporter struct {
+	next sdktrace.SpanExporter
+	r    *Redactor
+}
+
+// NewSpanExporter wraps next so every span is redacted by r.
+func NewSpanExporter(next sdktrace.SpanExporter, r *Redactor) *SpanExporter {
+	return &SpanExporter{next: next, r: r}
+}
+
+func (e *SpanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	redacted := make([]sdktrace.ReadOnlySpan, len(spans))
+	for i, s := range spans {
+		redacted[i] = redactedSpan{ReadOnlySpan: s, r: e.r}
+	}
+	return e.next.ExportSpans(ctx, redacted)
+}
+
+func (e *SpanExporter) Shutdown(ctx context.Context) error {
+	return e.next.Shutdown(ctx)
+}
+
+// redactedSpan overrides the parts of a span that can carry upstream
+// responses, arguments and credentials.
+type redactedSpan struct {
+	sdktrace.ReadOnlySpan
+	r *Redactor
+}
+
+func (s redactedSpan) Attributes() []attribute.KeyValue {
+	return s.attrs(s.ReadOnlySpan.Attributes())
+}
+
+func (s redactedSpan) Status() sdktrace.Status {
+	status := s.ReadOnlySpan.Status()
+	status.Description = s.r.String(status.Description)
+	return status
+}
+
+func (s redactedSpan) Events() []sdktrace.Event {
+	events := s.ReadOnlySpan.Events()
+	out := make([]sdktrace.Event, len(events))
+	for i, ev := range events {
+		ev.Name = s.r.String(ev.Name)
+		ev.Attributes = s.attrs(ev.Attributes)
+		out[i] = ev
+	}
+	return out
+}
+
+func (s redactedSpan) attrs(attrs []attribute.KeyValue) []attribute.KeyValue {
+	out := make([]attribute.KeyValue, len(attrs))
+	for i, kv := range attrs {
+		switch {
+		case kv.Value.Type() != attribute.STRING:
+		case urlAttributes[kv.Key]:
+			kv.Value = attribute.StringValue(s.r.URL(kv.Value.AsString()))
+		case kv.Key == "url.query":
+			kv.Value = attribute.StringValue(strings.TrimPrefix(s.r.URL("?"+kv.Value.AsString()), "?"))
+		default:
+			kv.Value = attribute.StringValue(s.r.String(kv.Value.AsString()))
+		}
+		out[i] = kv
+	}
+	return out
+}
@@ -0,0 +1,66 @@
+package redact
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Handler is a slog.Handler that redacts messages and attributes before
+// passing records to the wrapped handler.
+type Handler struct {
+	next slog.Handler
+	r    *Redactor
+}
+
+// NewHandler wraps next so every record is redacted by r.
+func NewHandler(next slog.Handler, r *Redactor) *Handler {
+	return &Handler{next: next, r: r}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, rec slog.Record) error {
+	out := slog.NewRecord(rec.Time, rec.Level, h.r.String(rec.Message), rec.PC)
+	rec.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.attr(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.attr(a)
+	}
+	return &Handler{next: h.next.WithAttrs(redacted), r: h.r}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name), r: h.r}
+}
+
+func (h *Handler) attr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if h.r.IsSensitive(a.Key) {
+		return slog.String(a.Key, Mask)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, h.r.String(a.Value.String()))
+	case slog.KindGroup:
+		group := a.Value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, g := range group {
+			redacted[i] = h.attr(g)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+	case slog.KindAny:
+		return slog.Any(a.Key, h.r.Value(a.Value.Any()))
+	default:
+		return a
+	}
+}
@@ -0,0 +1,229 @@
+// Package redact masks secrets and personal data before they reach logs and
+// traces.
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Mask replaces every redacted value.
+const Mask = "[REDACTED]"
+
+// minSecretLen keeps very short values from masking unrelated text.
+const minSecretLen = 4
+
+// pattern is a regular expression with an optional check on each match.
+type pattern struct {
+	re    *regexp.Regexp
+	valid func(match string) bool
+}
+
+// Builtin patterns that can be enabled by name in the manifest.
+var Builtin = map[string]pattern{
+	"email": {re: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)},
+	"phone": {re: regexp.MustCompile(`\+?\d{1,3}?[\s.\-]?\(?\d{3}\)?[\s.\-]\d{3}[\s.\-]\d{4}\b`)},
+	// Card numbers must pass the Luhn check so long IDs and timestamps are left alone
+	"card": {re: regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`), valid: luhn},
+}
+
+// DefaultPatterns are enabled when the manifest does not configure any.
+var DefaultPatterns = []string{"email", "phone", "card"}
+
+// sensitiveHeaders are always masked when they appear as keys.
+var sensitiveHeaders = []string{"authorization", "proxy-authorization", "cookie", "set-cookie", "x-api-key"}
+
+var bearerPattern = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*`)
+
+// Config is the manifest's redact section.
+type Config struct {
+	Fields   []string `yaml:"fields"`   // Argument and response field names masked wherever they appear
+	Patterns []string `yaml:"patterns"` // Builtin pattern names ("email", "phone", "card") or regular expressions
+}
+
+// Redactor masks known secret values, sensitive keys and pattern matches.
+type Redactor struct {
+	Secrets func() []string // Current secret values, looked up on every call so rotations are covered
+
+	fields   map[string]bool
+	patterns []pattern
+}
+
+// New builds a redactor from the config and additional sensitive field names.
+func New(cfg Config, fields ...string) (*Redactor, error) {
+	r := &Redactor{fields: make(map[string]bool)}
+	for _, f := range append(cfg.Fields, fields...) {
+		r.fields[strings.ToLower(f)] = true
+	}
+	for _, h := range sensitiveHeaders {
+		r.fields[h] = true
+	}
+
+	patterns := cfg.Patterns
+	if patterns == nil {
+		patterns = DefaultPatterns
+	}
+	for _, p := range patterns {
+		if builtin, ok := Builtin[p]; ok {
+			r.patterns = append(r.patterns, builtin)
+			continue
+		}
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, pattern{re: re})
+	}
+	return r, nil
+}
+
+// IsSensitive reports whether values under key are always masked.
+func (r *Redactor) IsSensitive(key string) bool {
+	return r.fields[strings.ToLower(key)]
+}
+
+// String masks secrets, credentials and pattern matches in s. JSON documents
+// also have sensitive fields masked.
+func (r *Redactor) String(s string) string {
+	if s == "" {
+		return s
+	}
+	if trimmed := strings.TrimSpace(s); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		var doc interface{}
+		if err := json.Unmarshal([]byte(trimmed), &doc); err == nil {
+			if b, err := json.Marshal(r.Value(doc)); err == nil {
+				s = string(b)
+			}
+		}
+	}
+
+	if r.Secrets != nil {
+		for _, secret := range r.Secrets() {
+			if len(secret) >= minSecretLen {
+				s = strings.ReplaceAll(s, secret, Mask)
+			}
+		}
+	}
+	s = bearerPattern.ReplaceAllString(s, "$1 "+Mask)
+	for _, p := range r.patterns {
+		s = p.re.ReplaceAllStringFunc(s, func(match string) string {
+			if p.valid != nil && !p.valid(match) {
+				return match
+			}
+			return Mask
+		})
+	}
+	return s
+}
+
+// URL masks the decoded path and query values of u, which String alone
+// would miss while they are percent-encoded. u may also be a path with a
+// query. The result is for reading, not for sending.
+func (r *Redactor) URL(u string) string {
+	if u == "" {
+		return ""
+	}
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return r.String(u)
+	}
+	var out string
+	if parsed.Host != "" {
+		out = parsed.Scheme + "://" + parsed.Host
+	}
+	out += r.String(parsed.Path)
+	query := parsed.Query()
+	if len(query) == 0 {
+		return out
+	}
+	keys := slices.Sorted(maps.Keys(query))
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		for _, v := range query[k] {
+			if r.IsSensitive(k) {
+				v = Mask
+			}
+			pairs = append(pairs, k+"="+r.String(v))
+		}
+	}
+	return out + "?" + strings.Join(pairs, "&")
+}
+
+// Value returns a copy of v with sensitive keys and string contents masked.
+func (r *Redactor) Value(v interface{}) interface{} {
+	switch val := v.(type) {
+	case string:
+		return r.String(val)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			if r.IsSensitive(k) {
+				out[k] = Mask
+				continue
+			}
+			out[k] = r.Value(item)
+		}
+		return out
+	case map[string]string:
+		out := make(map[string]string, len(val))
+		for k, item := range val {
+			if r.IsSensitive(k) {
+				out[k] = Mask
+				continue
+			}
+			out[k] = r.String(item)
+		}
+		return out
+	case http.Header:
+		out := make(http.Header, len(val))
+		for k, items := range val {
+			if r.IsSensitive(k) {
+				out[k] = []string{Mask}
+				continue
+			}
+			for _, item := range items {
+				out[k] = append(out[k], r.String(item))
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = r.Value(item)
+		}
+		return out
+	case error:
+		return r.String(val.Error())
+	case nil, bool, int, int64, float64:
+		return val
+	default:
+		return r.String(fmt.Sprintf("%+v", val))
+	}
+}
+
+// luhn reports whether the digits in s pass the Luhn checksum.
+func luhn(s string) bool {
+	sum, double := 0, false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
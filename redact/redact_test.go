@@ -0,0 +1,111 @@
+package redact
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const token = "tok_4f9a1c2e7b"
+
+func newRedactor(t *testing.T) *Redactor {
+	t.Helper()
+	r, err := New(Config{Fields: []string{"password"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Secrets = func() []string { return []string{token} }
+	return r
+}
+
+func TestHandlerRedactsAttrs(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(*slog.Logger)
+	}{
+		{name: "message", log: func(l *slog.Logger) { l.Info("calling with " + token) }},
+		{name: "string attr", log: func(l *slog.Logger) { l.Info("call", "url", "https://api.example.com/?key="+token) }},
+		{name: "bearer header", log: func(l *slog.Logger) { l.Info("call", "header", "Bearer abc.def-ghi") }},
+		{name: "sensitive key", log: func(l *slog.Logger) { l.Info("call", "Authorization", "abc.def-ghi") }},
+		{name: "configured field", log: func(l *slog.Logger) { l.Info("call", "password", "abc.def-ghi") }},
+		{name: "group", log: func(l *slog.Logger) { l.Info("call", slog.Group("req", "token", token)) }},
+		{name: "any", log: func(l *slog.Logger) { l.Info("call", "args", map[string]any{"password": "abc.def-ghi"}) }},
+		{name: "error", log: func(l *slog.Logger) { l.Info("call", "err", errors.New("upstream rejected "+token)) }},
+		{name: "with attrs", log: func(l *slog.Logger) { l.With("token", token).Info("call") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.log(slog.New(NewHandler(slog.NewJSONHandler(&buf, nil), newRedactor(t))))
+			out := buf.String()
+			if strings.Contains(out, token) || strings.Contains(out, "abc.def-ghi") {
+				t.Errorf("secret logged: %s", out)
+			}
+			if !strings.Contains(out, Mask) {
+				t.Errorf("nothing masked: %s", out)
+			}
+		})
+	}
+}
+
+func TestSpanExporterRedactsErrors(t *testing.T) {
+	mem := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(NewSpanExporter(mem, newRedactor(t))))
+	_, span := tp.Tracer("test").Start(context.Background(), "ExecuteAction")
+	err := errors.New("upstream rejected Bearer " + token)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	span.SetAttributes(
+		attribute.String("url.full", "https://api.example.com/v1?api_key="+token),
+		attribute.String("url.query", "api_key="+token),
+		attribute.String("action.args", `{"password": "abc.def-ghi"}`),
+	)
+	span.End()
+
+	spans := mem.GetSpans().Snapshots()
+	if len(spans) != 1 {
+		t.Fatalf("exported %d spans, want 1", len(spans))
+	}
+	got := spans[0]
+	values := map[string]string{"status": got.Status().Description}
+	for _, kv := range got.Attributes() {
+		values[string(kv.Key)] = kv.Value.AsString()
+	}
+	for _, ev := range got.Events() {
+		for _, kv := range ev.Attributes {
+			values[ev.Name+" "+string(kv.Key)] = kv.Value.AsString()
+		}
+	}
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{name: "status description", key: "status"},
+		{name: "error event", key: "exception exception.message"},
+		{name: "url attribute", key: "url.full"},
+		{name: "query attribute", key: "url.query"},
+		{name: "json attribute", key: "action.args"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, ok := values[tt.key]
+			if !ok {
+				t.Fatalf("span has no %s", tt.key)
+			}
+			if strings.Contains(v, token) || strings.Contains(v, "abc.def-ghi") {
+				t.Errorf("%s = %q, secret exported", tt.key, v)
+			}
+			if !strings.Contains(v, Mask) {
+				t.Errorf("%s = %q, nothing masked", tt.key, v)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// execTimeout bounds how long a helper command may run.
+const execTimeout = 10 * time.Second
+
+// execProvider runs an external helper and uses its stdout as the value. The
+// secret name is passed in the YAFAI_SECRET_NAME environment variable.
+type execProvider struct {
+	Command []string
+}
+
+func (p execProvider) Get(ctx context.Context, name string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, execTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, p.Command[0], p.Command[1:]...)
+	cmd.Env = append(os.Environ(), "YAFAI_SECRET_NAME="+name)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// stderr is reported, stdout never is since it may hold the value
+		return "", fmt.Errorf("helper %s failed: %w: %s", p.Command[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	value := strings.TrimRight(stdout.String(), "\r\n")
+	if value == "" {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
@@ -0,0 +1,240 @@
+// Package secrets resolves credentials referenced from skill manifests as
+// secret://name through pluggable providers.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scheme prefixes secret references in manifest values.
+const Scheme = "secret://"
+
+// SkillKey names the key passed with --skill_key, used as the default bearer token.
+const SkillKey = "skill_key"
+
+// DefaultTTL is how long resolved values are cached before they are read again.
+const DefaultTTL = 5 * time.Minute
+
+// ErrNotFound is returned when no provider has a value for a secret.
+var ErrNotFound = errors.New("secret not found")
+
+var refPattern = regexp.MustCompile(`secret://([A-Za-z0-9_.\-]+)`)
+
+// Spec configures where a named secret comes from.
+type Spec struct {
+	Provider string   `yaml:"provider"` // "env", "file", "store" or "exec"
+	Env      string   `yaml:"env"`      // env: variable name, defaults to the upper-cased secret name
+	Path     string   `yaml:"path"`     // file: path to a file holding the value (e.g. a mounted Kubernetes secret)
+	Command  []string `yaml:"command"`  // exec: helper command printing the value on stdout
+	TTL      string   `yaml:"ttl"`      // How long the value is cached, defaults to 5m
+}
+
+// Provider looks up secret values by name.
+type Provider interface {
+	Get(ctx context.Context, name string) (string, error)
+}
+
+type cached struct {
+	value   string
+	expires time.Time
+}
+
+// Resolver resolves secret names through their configured providers and
+// caches the values in memory. Names without a spec are looked up in memory,
+// then the local store, then the environment.
+type Resolver struct {
+	specs  map[string]*Spec
+	store  *Store
+	static map[string]string
+
+	mu    sync.Mutex
+	cache map[string]cached
+}
+
+// NewResolver validates the specs and returns a resolver backed by store,
+// which may be nil.
+func NewResolver(specs map[string]*Spec, store *Store) (*Resolver, error) {
+	for name, spec := range specs {
+		if _, err := spec.provider(name, store); err != nil {
+			return nil, fmt.Errorf("secret %q: %w", name, err)
+		}
+		if spec.TTL != "" {
+			if _, err := time.ParseDuration(spec.TTL); err != nil {
+				return nil, fmt.Errorf("secret %q: invalid ttl %q: %w", name, spec.TTL, err)
+			}
+		}
+	}
+	return &Resolver{
+		specs:  specs,
+		store:  store,
+		static: make(map[string]string),
+		cache:  make(map[string]cached),
+	}, nil
+}
+
+// Set registers an in-memory value for name, e.g. a key passed on the command line.
+func (r *Resolver) Set(name, value string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.static[name] = value
+	delete(r.cache, name)
+}
+
+// Resolve returns the value of the named secret.
+func (r *Resolver) Resolve(ctx context.Context, name string) (string, error) {
+	r.mu.Lock()
+	if v, ok := r.static[name]; ok {
+		r.mu.Unlock()
+		return v, nil
+	}
+	if c, ok := r.cache[name]; ok && time.Now().Before(c.expires) {
+		r.mu.Unlock()
+		return c.value, nil
+	}
+	r.mu.Unlock()
+
+	value, ttl, err := r.lookup(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("resolving secret %q: %w", name, err)
+	}
+
+	r.mu.Lock()
+	r.cache[name] = cached{value: value, expires: time.Now().Add(ttl)}
+	r.mu.Unlock()
+	return value, nil
+}
+
+func (r *Resolver) lookup(ctx context.Context, name string) (string, time.Duration, error) {
+	spec, ok := r.specs[name]
+	if !ok {
+		if r.store != nil {
+			v, err := r.store.Lookup(name)
+			if err == nil {
+				return v, DefaultTTL, nil
+			}
+			if !errors.Is(err, ErrNotFound) {
+				return "", 0, err
+			}
+		}
+		v, err := envProvider{}.Get(ctx, name)
+		return v, DefaultTTL, err
+	}
+
+	p, err := spec.provider(name, r.store)
+	if err != nil {
+		return "", 0, err
+	}
+	v, err := p.Get(ctx, name)
+	if err != nil {
+		return "", 0, err
+	}
+	ttl := DefaultTTL
+	if spec.TTL != "" {
+		ttl, _ = time.ParseDuration(spec.TTL)
+	}
+	return v, ttl, nil
+}
+
+// Expand replaces every secret://name reference in s with its value.
+func (r *Resolver) Expand(ctx context.Context, s string) (string, error) {
+	if !strings.Contains(s, Scheme) {
+		return s, nil
+	}
+	var firstErr error
+	out := refPattern.ReplaceAllStringFunc(s, func(ref string) string {
+		v, err := r.Resolve(ctx, strings.TrimPrefix(ref, Scheme))
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		return v
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+	return out, nil
+}
+
+// Flush drops cached values so the next lookup reads them from their provider again.
+func (r *Resolver) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cache = make(map[string]cached)
+}
+
+// Values returns the secret values currently held in memory.
+func (r *Resolver) Values() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	values := make([]string, 0, len(r.static)+len(r.cache))
+	for _, v := range r.static {
+		values = append(values, v)
+	}
+	for _, c := range r.cache {
+		values = append(values, c.value)
+	}
+	return values
+}
+
+func (s *Spec) provider(name string, store *Store) (Provider, error) {
+	switch s.Provider {
+	case "", "env":
+		return envProvider{Var: s.Env}, nil
+	case "file":
+		if s.Path == "" {
+			return nil, errors.New("file provider needs a path")
+		}
+		return fileProvider{Path: s.Path}, nil
+	case "store":
+		if store == nil {
+			return nil, errors.New("no local secret store is available")
+		}
+		return store, nil
+	case "exec":
+		if len(s.Command) == 0 {
+			return nil, errors.New("exec provider needs a command")
+		}
+		return execProvider{Command: s.Command}, nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q", s.Provider)
+	}
+}
+
+// envProvider reads the value from an environment variable.
+type envProvider struct {
+	Var string
+}
+
+func (p envProvider) Get(_ context.Context, name string) (string, error) {
+	key := p.Var
+	if key == "" {
+		key = strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+	}
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
+
+// fileProvider reads the value from a file, trimming trailing newlines.
+type fileProvider struct {
+	Path string
+}
+
+func (p fileProvider) Get(_ context.Context, _ string) (string, error) {
+	b, err := os.ReadFile(p.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
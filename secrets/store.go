@@ -0,0 +1,201 @@
+package secrets
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// KeyEnv overrides the store's key file with a base64 encoded 32 byte key.
+const KeyEnv = "YAFAI_SECRETS_KEY"
+
+// Store is an encrypted local secret store. Values are kept in a single
+// AES-256-GCM encrypted file next to a key file readable only by the owner.
+// The key file guards copies of the encrypted file, not the directory: anyone
+// who can read both can decrypt the store unless the key comes from KeyEnv.
+type Store struct {
+	Path    string
+	KeyPath string
+
+	mu sync.Mutex
+}
+
+// OpenStore returns the store kept in dir (normally ~/.yafai).
+func OpenStore(dir string) *Store {
+	return &Store{
+		Path:    filepath.Join(dir, "secrets.enc"),
+		KeyPath: filepath.Join(dir, "secrets.key"),
+	}
+}
+
+// Get implements Provider.
+func (s *Store) Get(_ context.Context, name string) (string, error) {
+	return s.Lookup(name)
+}
+
+// Lookup returns the stored value for name.
+func (s *Store) Lookup(name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	v, ok := values[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return v, nil
+}
+
+// Set stores value under name, replacing any previous value.
+func (s *Store) Set(name, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values, err := s.load()
+	if err != nil {
+		return err
+	}
+	values[name] = value
+	return s.save(values)
+}
+
+// Delete removes name from the store.
+func (s *Store) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := values[name]; !ok {
+		return ErrNotFound
+	}
+	delete(values, name)
+	return s.save(values)
+}
+
+// Names lists the stored secret names.
+func (s *Store) Names() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *Store) load() (map[string]string, error) {
+	values := make(map[string]string)
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading secret store: %w", err)
+	}
+
+	gcm, err := s.cipher(false)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("secret store is corrupt")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("secret store cannot be decrypted with the current key")
+	}
+	if err := json.Unmarshal(plain, &values); err != nil {
+		return nil, fmt.Errorf("decoding secret store: %w", err)
+	}
+	return values, nil
+}
+
+func (s *Store) save(values map[string]string) error {
+	plain, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	gcm, err := s.cipher(true)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	data := gcm.Seal(nonce, nonce, plain, nil)
+
+	// Write to a temporary file first so a crash never leaves a truncated store
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("writing secret store: %w", err)
+	}
+	return os.Rename(tmp, s.Path)
+}
+
+// cipher loads the store key, creating the key file when create is set.
+func (s *Store) cipher(create bool) (cipher.AEAD, error) {
+	key, err := s.key(create)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *Store) key(create bool) ([]byte, error) {
+	if encoded := os.Getenv(KeyEnv); encoded != "" {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("%s must be a base64 encoded 32 byte key", KeyEnv)
+		}
+		return key, nil
+	}
+
+	key, err := os.ReadFile(s.KeyPath)
+	if err == nil {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("secret store key %s is invalid", s.KeyPath)
+		}
+		return key, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) || !create {
+		return nil, fmt.Errorf("reading secret store key: %w", err)
+	}
+
+	key = make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.KeyPath), 0700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(s.KeyPath, key, 0600); err != nil {
+		return nil, fmt.Errorf("writing secret store key: %w", err)
+	}
+	return key, nil
+}